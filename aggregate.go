@@ -1,6 +1,10 @@
 package sajari
 
-import pb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+import (
+	"fmt"
+
+	pb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+)
 
 // Aggregate is an interface which is implemented by all aggregate
 // types in this package.
@@ -96,6 +100,92 @@ func (b Bucket) proto() (*pb.Aggregate_Bucket_Bucket, error) {
 	}, nil
 }
 
+// matchedBucket is the name of the bucket added by OrderedBuckets to count records
+// which match any of the buckets.
+const matchedBucket = "_matched"
+
+// OrderedBuckets returns an aggregate which is like BucketAggregate, but whose response can
+// be read in the order the buckets are defined (see OrderedBucketAggregate.Buckets).  It also
+// counts the records which match at least one bucket.  If other is non-empty then records
+// which don't match any bucket are counted in an additional bucket with that name.
+func OrderedBuckets(other string, bs ...Bucket) *OrderedBucketAggregate {
+	return &OrderedBucketAggregate{
+		other:   other,
+		buckets: bs,
+	}
+}
+
+// OrderedBucketAggregate is an aggregate which counts records in a list of buckets.  Use
+// OrderedBuckets to create one.
+type OrderedBucketAggregate struct {
+	other   string
+	buckets []Bucket
+}
+
+func (a *OrderedBucketAggregate) proto() (*pb.Aggregate, error) {
+	fs := make([]Filter, 0, len(a.buckets))
+	for _, b := range a.buckets {
+		if b.Name == matchedBucket || (a.other != "" && b.Name == a.other) {
+			return nil, fmt.Errorf("bucket name %q is reserved", b.Name)
+		}
+		fs = append(fs, b.Filter)
+	}
+
+	bs := make([]Bucket, 0, len(a.buckets)+2)
+	bs = append(bs, a.buckets...)
+	bs = append(bs, Bucket{
+		Name:   matchedBucket,
+		Filter: AnyFilter(fs...),
+	})
+	if a.other != "" {
+		bs = append(bs, Bucket{
+			Name:   a.other,
+			Filter: NoneOfFilters(fs...),
+		})
+	}
+	return bucketAggregate{bs}.proto()
+}
+
+// OrderedBucketsResponse is the response of an OrderedBucketAggregate.
+type OrderedBucketsResponse struct {
+	// Buckets are the bucket counts, in the order the buckets were defined.
+	Buckets []BucketResponse
+
+	// Matched is the number of records which match at least one bucket.
+	Matched int
+
+	// Other is the count of records which don't match any bucket, if an other bucket was
+	// requested.
+	Other *BucketResponse
+}
+
+// Buckets returns the bucket counts from the aggregate response v, the value in
+// Results.Aggregates for this aggregate.
+func (a *OrderedBucketAggregate) Buckets(v interface{}) (*OrderedBucketsResponse, error) {
+	br, ok := v.(BucketsResponse)
+	if !ok {
+		return nil, fmt.Errorf("expected BucketsResponse, got %T", v)
+	}
+
+	out := &OrderedBucketsResponse{
+		Buckets: make([]BucketResponse, 0, len(a.buckets)),
+		Matched: br[matchedBucket].Count,
+	}
+	for _, b := range a.buckets {
+		out.Buckets = append(out.Buckets, BucketResponse{
+			Name:  b.Name,
+			Count: br[b.Name].Count,
+		})
+	}
+	if a.other != "" {
+		out.Other = &BucketResponse{
+			Name:  a.other,
+			Count: br[a.other].Count,
+		}
+	}
+	return out, nil
+}
+
 // MaxAggregate computes the maximum value of a numeric field over a result set.
 func MaxAggregate(field string) Aggregate {
 	return maxAggregate(field)
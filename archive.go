@@ -0,0 +1,93 @@
+package sajari
+
+import (
+	"golang.org/x/net/context"
+)
+
+// DefaultArchivedField is the name of the boolean field used to mark records as archived
+// (see Client.Archive), unless another is set using WithArchivedField.  The field must be
+// added to the collection schema before records are archived, e.g. using Schema.Add with
+// Client.ArchivedFieldSchema.
+const DefaultArchivedField = "sajari_archived"
+
+// WithArchivedField sets the name of the boolean field used to mark records as archived
+// (see Client.Archive), for collections where DefaultArchivedField is already in use.
+func WithArchivedField(name string) Opt {
+	return func(c *Client) {
+		c.archivedField = name
+	}
+}
+
+// archived returns the name of the field used to mark records as archived.
+func (c *Client) archived() string {
+	if c.archivedField != "" {
+		return c.archivedField
+	}
+	return DefaultArchivedField
+}
+
+// ArchivedFieldSchema returns the schema definition of the field used by the client to mark
+// records as archived (see DefaultArchivedField and WithArchivedField).
+func (c *Client) ArchivedFieldSchema() Field {
+	return Field{
+		Name:        c.archived(),
+		Description: "Set on records which are archived, and excluded from search.",
+		Type:        TypeBoolean,
+	}
+}
+
+// WithArchivedExcluded configures the client to exclude archived records (see Client.Archive)
+// from the results of Query.Search and Query.SearchInto, unless Request.IncludeArchived
+// is set.  It is not enabled by default as the filter requires the archived field to be
+// defined in the collection schema.
+func WithArchivedExcluded() Opt {
+	return func(c *Client) {
+		c.excludeArchivedRecords = true
+	}
+}
+
+// Archive marks the record identified by k as archived, so that it is excluded from search
+// results by clients configured with WithArchivedExcluded.  The record is not removed, and
+// can be restored with Restore.
+func (c *Client) Archive(ctx context.Context, k *Key) error {
+	return c.Mutate(ctx, k, SetField(c.archived(), true))
+}
+
+// Restore clears the archived mark set on the record identified by k by Archive.
+func (c *Client) Restore(ctx context.Context, k *Key) error {
+	return c.Mutate(ctx, k, SetField(c.archived(), false))
+}
+
+// ArchiveMulti marks the records identified by ks as archived (see Archive).  If any of the
+// mutations fail then a MultiError will be returned with errors set in the respective
+// indexes.
+func (c *Client) ArchiveMulti(ctx context.Context, ks []*Key) error {
+	return c.MutateAll(ctx, ks, SetField(c.archived(), true))
+}
+
+// RestoreMulti clears the archived mark on the records identified by ks (see Restore).  If
+// any of the mutations fail then a MultiError will be returned with errors set in the
+// respective indexes.
+func (c *Client) RestoreMulti(ctx context.Context, ks []*Key) error {
+	return c.MutateAll(ctx, ks, SetField(c.archived(), false))
+}
+
+// excludeArchived returns a copy of r with its filter restricted to records which aren't
+// archived, if enabled (see WithArchivedExcluded).  Otherwise r is returned.
+func (c *Client) excludeArchived(r *Request) *Request {
+	if !c.excludeArchivedRecords || r.IncludeArchived {
+		return r
+	}
+
+	// Records which have never been archived don't have the field set, so match
+	// records which are not archived rather than those which are unarchived.
+	notArchived := NoneOfFilters(FieldFilter(c.archived()+" =", true))
+
+	rc := r.Clone()
+	if rc.Filter == nil {
+		rc.Filter = notArchived
+	} else {
+		rc.Filter = AllFilters(rc.Filter, notArchived)
+	}
+	return &rc
+}
@@ -2,6 +2,8 @@
 package autocomplete
 
 import (
+	"sort"
+
 	"golang.org/x/net/context"
 
 	"code.sajari.com/sajari-sdk-go"
@@ -18,7 +20,8 @@ func New(client *sajari.Client, name string) *Client {
 	}
 }
 
-// Client provides methods for interacting directly with auto-complete models.
+// Client provides methods for interacting directly with auto-complete models.  It is safe
+// for concurrent use.
 type Client struct {
 	c *sajari.Client
 
@@ -45,6 +48,66 @@ func (c *Client) TrainCorpus(ctx context.Context, terms []string) error {
 	return err
 }
 
+// MaxCorpusOccurrences is the total number of term occurrences sent by TrainCorpusFrequencies.
+// Larger totals are scaled down to this size.
+const MaxCorpusOccurrences = 100000
+
+// TrainCorpusFrequencies trains the autocomplete model for spelling correction like TrainCorpus,
+// using the number of occurrences of each term in freqs to weight the terms.  Terms with a
+// frequency less than 1 are ignored.
+//
+// Each term is sent once per occurrence.  If the frequencies total more than
+// MaxCorpusOccurrences then they are scaled down to that total: each term is sent once, and
+// the remaining occurrences are shared between terms in proportion to their frequencies.  If
+// freqs has more than MaxCorpusOccurrences terms then each term is sent exactly once.
+func (c *Client) TrainCorpusFrequencies(ctx context.Context, freqs map[string]int) error {
+	return c.TrainCorpus(ctx, expandFrequencies(freqs, MaxCorpusOccurrences))
+}
+
+// expandFrequencies returns the terms in freqs, each repeated according to its frequency.
+// If the frequencies total more than limit then each term occurs once and the remaining
+// limit is shared in proportion to the frequencies, so the total is at most limit (or the
+// number of terms, if that is larger).  Terms are sorted.
+func expandFrequencies(freqs map[string]int, limit int) []string {
+	keys := make([]string, 0, len(freqs))
+	var total int64
+	for k, f := range freqs {
+		if f < 1 {
+			continue
+		}
+		keys = append(keys, k)
+		total += int64(f)
+	}
+	sort.Strings(keys)
+
+	// Beyond the first occurrence of each term, spare occurrences are shared
+	// in proportion to the extra occurrences requested.
+	spare := int64(limit) - int64(len(keys))
+	if spare < 0 {
+		spare = 0
+	}
+	extra := total - int64(len(keys))
+	scaled := extra > spare
+	left := spare
+
+	var terms []string
+	for _, k := range keys {
+		n := int64(freqs[k] - 1)
+		if scaled {
+			n = int64(float64(n) * float64(spare) / float64(extra))
+		}
+		if n > left {
+			// Guard against rounding taking the total over the limit.
+			n = left
+		}
+		left -= n
+		for i := int64(0); i <= n; i++ {
+			terms = append(terms, k)
+		}
+	}
+	return terms
+}
+
 // TrainQuery takes a query phrase and uses it to train an autocomplete model for partial queries. The
 // phrase should be a successful query (i.e. good spelling and return useful results).
 func (c *Client) TrainQuery(ctx context.Context, phrase string) error {
@@ -0,0 +1,75 @@
+package autocomplete
+
+import (
+	"reflect"
+	"testing"
+)
+
+func countTerms(terms []string) map[string]int {
+	out := make(map[string]int)
+	for _, t := range terms {
+		out[t]++
+	}
+	return out
+}
+
+func TestExpandFrequencies(t *testing.T) {
+	tests := []struct {
+		freqs map[string]int
+		max   int
+		want  map[string]int
+	}{
+		{
+			freqs: map[string]int{"a": 3, "b": 1, "c": 0, "d": -2},
+			max:   100,
+			want:  map[string]int{"a": 3, "b": 1},
+		},
+		{
+			freqs: map[string]int{"a": 600, "b": 300, "c": 100},
+			max:   100,
+			want:  map[string]int{"a": 59, "b": 30, "c": 10},
+		},
+		{
+			// Rare terms are kept.
+			freqs: map[string]int{"a": 1000000, "b": 1},
+			max:   10,
+			want:  map[string]int{"a": 9, "b": 1},
+		},
+		{
+			// Every term takes a slot before the rest are shared out.
+			freqs: map[string]int{"a": 100, "b": 1, "c": 1, "d": 1, "e": 1, "f": 1, "g": 1, "h": 1, "i": 1, "j": 1},
+			max:   10,
+			want:  map[string]int{"a": 1, "b": 1, "c": 1, "d": 1, "e": 1, "f": 1, "g": 1, "h": 1, "i": 1, "j": 1},
+		},
+		{
+			freqs: map[string]int{"a": 100, "b": 1, "c": 1},
+			max:   10,
+			want:  map[string]int{"a": 8, "b": 1, "c": 1},
+		},
+		{
+			// More terms than the limit: each is sent once.
+			freqs: map[string]int{"a": 5, "b": 5, "c": 5},
+			max:   2,
+			want:  map[string]int{"a": 1, "b": 1, "c": 1},
+		},
+		{
+			freqs: map[string]int{"a": 1 << 30, "b": 1 << 30, "c": 1 << 30},
+			max:   MaxCorpusOccurrences,
+			want:  map[string]int{"a": MaxCorpusOccurrences / 3, "b": MaxCorpusOccurrences / 3, "c": MaxCorpusOccurrences / 3},
+		},
+	}
+
+	for _, tt := range tests {
+		terms := expandFrequencies(tt.freqs, tt.max)
+		if got := countTerms(terms); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("expandFrequencies(%v, %d) counts = %v, expected %v", tt.freqs, tt.max, got, tt.want)
+		}
+		max := tt.max
+		if len(tt.want) > max {
+			max = len(tt.want)
+		}
+		if len(terms) > max {
+			t.Errorf("expandFrequencies(%v, %d) returned %d terms, expected at most %d", tt.freqs, tt.max, len(terms), max)
+		}
+	}
+}
@@ -22,7 +22,8 @@ func New(client *sajari.Client) *Client {
 	}
 }
 
-// Client provides methods for interacting with bayes models.
+// Client provides methods for interacting with bayes models.  It is safe for concurrent use,
+// as are the Model and TrainingSet handles it creates.
 type Client struct {
 	c *sajari.Client
 }
@@ -88,7 +89,7 @@ func (t *TrainingSet) AddRecord(ctx context.Context, class Class, data []string)
 
 // Info returns info about a training set such as class names
 func (t *TrainingSet) Classes(ctx context.Context) ([]Class, error) {
-	cls, err := tspb.NewTrainingSetClient(t.c.ClientConn).Info(ctx, &tspb.InfoRequest{
+	cls, err := tspb.NewTrainingSetClient(t.c.ClientConn).Info(t.newContext(ctx), &tspb.InfoRequest{
 		Name: t.name,
 	})
 	if err != nil {
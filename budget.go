@@ -0,0 +1,89 @@
+package sajari
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Budget divides the time remaining before a context's deadline between a sequence of
+// attempts (e.g. a request and its retries), so that the attempts together respect the
+// caller's deadline rather than each attempt being allowed the full deadline.
+//
+// Budget is for attempts made one after another by the caller.  It does not apply to
+// hedged requests (see WithHedging): hedged attempts run concurrently within a single
+// call, and each is given the deadline of the context passed to that call.
+//
+// For example, to allow the first attempt 60% of the time, the second 30% and the last 10%:
+//
+//	b := sajari.NewBudget(ctx, 0.6, 0.3, 0.1)
+//	for i := 0; i < b.Attempts(); i++ {
+//		actx, cancel := b.Attempt(ctx, i)
+//		results, err = client.Query().Search(actx, r)
+//		cancel()
+//		if err == nil {
+//			break
+//		}
+//	}
+//
+// Time which is not used by an attempt is carried over to later attempts.  A Budget is
+// safe for concurrent use, so can be shared between concurrent attempts.
+type Budget struct {
+	start    time.Time
+	total    time.Duration
+	deadline bool
+	split    []float64
+}
+
+// NewBudget creates a Budget which divides the time remaining before the deadline of
+// ctx between attempts in proportion to split.  The values of split are normalised, so
+// need not sum to 1.  If ctx has no deadline then attempts are not given deadlines.
+func NewBudget(ctx context.Context, split ...float64) *Budget {
+	b := &Budget{
+		start: time.Now(),
+		split: make([]float64, 0, len(split)),
+	}
+
+	sum := 0.0
+	for _, s := range split {
+		if s > 0 {
+			sum += s
+		}
+	}
+	for _, s := range split {
+		if s < 0 || sum == 0 {
+			s = 0
+		}
+		if sum > 0 {
+			s /= sum
+		}
+		b.split = append(b.split, s)
+	}
+
+	if d, ok := ctx.Deadline(); ok {
+		b.deadline = true
+		b.total = d.Sub(b.start)
+	}
+	return b
+}
+
+// Attempts returns the number of attempts in the budget.
+func (b *Budget) Attempts() int {
+	return len(b.split)
+}
+
+// Attempt returns a context for attempt i (starting at 0).  Its deadline is the end of
+// the attempt's share of the budget, counted from when the Budget was created, so that
+// time left over by earlier attempts is available to later ones.  The deadline of the
+// last attempt is the deadline of ctx.
+func (b *Budget) Attempt(ctx context.Context, i int) (context.Context, context.CancelFunc) {
+	if !b.deadline || i >= len(b.split)-1 {
+		return context.WithCancel(ctx)
+	}
+
+	cum := 0.0
+	for _, s := range b.split[:i+1] {
+		cum += s
+	}
+	return context.WithDeadline(ctx, b.start.Add(time.Duration(cum*float64(b.total))))
+}
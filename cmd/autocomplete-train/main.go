@@ -7,15 +7,12 @@ import (
 
 	"golang.org/x/net/context"
 
-	"code.sajari.com/sajari-sdk-go"
 	"code.sajari.com/sajari-sdk-go/autocomplete"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
 )
 
 var (
-	endpoint   = flag.String("endpoint", "", "endpoint `address`, uses default if not set")
-	project    = flag.String("project", "", "project `name` to query")
-	collection = flag.String("collection", "", "collection `name` to query")
-	creds      = flag.String("creds", "", "calling credentials `key-id,key-secret`")
+	conn = cliutil.AddFlags(flag.CommandLine)
 
 	name  = flag.String("name", "en.dict", "`name` of autocomplete model to train")
 	terms = flag.String("terms", "", "comma-seperated list of correctly spelt words to add to autocomplete dictionary")
@@ -24,37 +21,9 @@ var (
 func main() {
 	flag.Parse()
 
-	var opts []sajari.Opt
-	if *endpoint != "" {
-		opts = append(opts, sajari.WithEndpoint(*endpoint))
-	}
-
-	if *project == "" {
-		log.Println("project: cannot be empty")
-		return
-	}
-
-	if *collection == "" {
-		log.Println("project: cannot be empty")
-		return
-	}
-
-	if *creds == "" {
-		log.Println("creds: cannot be empty")
-		return
-	}
-
-	credsSplit := strings.Split(*creds, ",")
-	if len(credsSplit) != 2 {
-		log.Printf("creds: expected 'id,secret', got '%v'", *creds)
-		return
-	}
-	kc := sajari.KeyCredentials(credsSplit[0], credsSplit[1])
-	opts = append(opts, sajari.WithCredentials(kc))
-
-	client, err := sajari.New(*project, *collection, opts...)
+	client, err := conn.NewClient()
 	if err != nil {
-		log.Printf("error from sajari.New(): %v", err)
+		log.Printf("error creating client: %v", err)
 		return
 	}
 	defer func() {
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go/autocomplete"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
+)
+
+var (
+	conn = cliutil.AddFlags(flag.CommandLine)
+
+	name     = flag.String("name", "en.dict", "`name` of autocomplete model")
+	complete = flag.String("complete", "", "`phrase` to complete")
+	terms    = flag.String("terms", "", "comma-seperated list of terms in the phrase, defaults to the words in the phrase")
+
+	trainCorpus  = flag.String("train-corpus", "", "train the model with correctly spelt terms read from `file`, one per line")
+	trainQueries = flag.String("train-queries", "", "train the model with successful query phrases read from `file`, one per line")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %v [flags]\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Prints completions for -complete, or trains the model with -train-corpus or -train-queries.")
+	fmt.Fprintln(os.Stderr, "Otherwise reads phrases from stdin, one per line, and prints completions for each.")
+	fmt.Fprintln(os.Stderr)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	client, err := conn.NewClient()
+	if err != nil {
+		log.Printf("error creating client: %v", err)
+		return
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("error closing Client: %v", err)
+		}
+	}()
+
+	ac := autocomplete.New(client, *name)
+	ctx := context.Background()
+
+	if *trainCorpus != "" {
+		lines, err := readLines(*trainCorpus)
+		if err != nil {
+			log.Printf("error reading -train-corpus: %v", err)
+			return
+		}
+		if err := ac.TrainCorpus(ctx, lines); err != nil {
+			log.Printf("error training corpus: %v", err)
+			return
+		}
+		log.Printf("Trained corpus with %d terms", len(lines))
+	}
+
+	if *trainQueries != "" {
+		lines, err := readLines(*trainQueries)
+		if err != nil {
+			log.Printf("error reading -train-queries: %v", err)
+			return
+		}
+		for i, l := range lines {
+			if err := ac.TrainQuery(ctx, l); err != nil {
+				log.Printf("error training query %q (%d of %d): %v", l, i+1, len(lines), err)
+				return
+			}
+		}
+		log.Printf("Trained %d queries", len(lines))
+	}
+
+	if *trainCorpus != "" || *trainQueries != "" {
+		return
+	}
+
+	if *complete != "" {
+		var ts []string
+		if *terms != "" {
+			ts = strings.Split(*terms, ",")
+		}
+		if err := printCompletions(ctx, ac, os.Stdout, *complete, ts); err != nil {
+			log.Println(err)
+		}
+		return
+	}
+
+	in := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Fprint(os.Stderr, "> ")
+		if !in.Scan() {
+			fmt.Fprintln(os.Stderr)
+			return
+		}
+
+		phrase := strings.TrimSpace(in.Text())
+		if phrase == "" {
+			continue
+		}
+		if err := printCompletions(ctx, ac, os.Stdout, phrase, nil); err != nil {
+			log.Println(err)
+		}
+	}
+}
+
+// printCompletions writes completions for phrase to w, one per line.  If terms
+// is empty then the words in phrase are used.
+func printCompletions(ctx context.Context, ac *autocomplete.Client, w io.Writer, phrase string, terms []string) error {
+	if len(terms) == 0 {
+		terms = strings.Fields(phrase)
+	}
+
+	phrases, err := ac.Complete(ctx, phrase, terms)
+	if err != nil {
+		return fmt.Errorf("error completing %q: %v", phrase, err)
+	}
+	for _, p := range phrases {
+		fmt.Fprintln(w, p)
+	}
+	return nil
+}
+
+// readLines reads the non-empty lines from the file at path.
+func readLines(path string) ([]string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var out []string
+	s := bufio.NewScanner(f)
+	for s.Scan() {
+		if l := strings.TrimSpace(s.Text()); l != "" {
+			out = append(out, l)
+		}
+	}
+	return out, s.Err()
+}
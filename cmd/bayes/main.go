@@ -0,0 +1,195 @@
+package main
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go/bayes"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
+)
+
+var (
+	conn = cliutil.AddFlags(flag.CommandLine)
+
+	set   = flag.String("set", "", "`name` of the training set")
+	model = flag.String("model", "", "`name` of the model to train or classify with")
+
+	create   = flag.Bool("create", false, "create the training set")
+	addClass = flag.String("add-class", "", "comma-seperated `list` of classes to add to the training set")
+	classes  = flag.Bool("classes", false, "list the classes in the training set")
+	class    = flag.String("class", "", "`name` of the class to upload records to")
+	upload   = flag.String("upload", "", "upload records to -class from `file` (- for stdin), one per line")
+	train    = flag.Bool("train", false, "train -model from the training set and print the results")
+	classify = flag.Bool("classify", false, "classify text read from stdin with -model, one record per line")
+)
+
+func main() {
+	flag.Parse()
+
+	if (*create || *addClass != "" || *classes || *upload != "" || *train) && *set == "" {
+		log.Println("set: cannot be empty")
+		return
+	}
+
+	if (*train || *classify) && *model == "" {
+		log.Println("model: cannot be empty")
+		return
+	}
+
+	client, err := conn.NewClient()
+	if err != nil {
+		log.Printf("error creating client: %v", err)
+		return
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("error closing Client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	b := bayes.New(client)
+	ts := b.TrainingSet(*set)
+
+	if *create {
+		if err := ts.Create(ctx); err != nil {
+			log.Printf("error creating training set: %v", err)
+			return
+		}
+	}
+
+	if *addClass != "" {
+		for _, c := range strings.Split(*addClass, ",") {
+			if _, err := ts.AddClass(ctx, c); err != nil {
+				log.Printf("error adding class %q: %v", c, err)
+				return
+			}
+		}
+	}
+
+	if *upload != "" {
+		if *class == "" {
+			log.Println("class: cannot be empty with -upload")
+			return
+		}
+		n, err := uploadRecords(ctx, ts, *class, *upload)
+		if err != nil {
+			log.Printf("error uploading records (%d uploaded): %v", n, err)
+			return
+		}
+		log.Printf("Uploaded %d records", n)
+	}
+
+	if *classes {
+		cs, err := ts.Classes(ctx)
+		if err != nil {
+			log.Printf("error fetching classes: %v", err)
+			return
+		}
+		for _, c := range cs {
+			fmt.Println(c.Name())
+		}
+	}
+
+	if *train {
+		res, err := ts.Train(ctx, *model)
+		if err != nil {
+			log.Printf("error training model: %v", err)
+			return
+		}
+		printTrainResults(os.Stdout, res)
+	}
+
+	if *classify {
+		if err := classifyLines(ctx, b.Model(*model), os.Stdin, os.Stdout); err != nil {
+			log.Printf("error classifying: %v", err)
+		}
+	}
+}
+
+// uploadRecords uploads each non-empty line read from path (or stdin if path
+// is "-") as a record in class, which is added if necessary.  Returns the number of records uploaded.
+func uploadRecords(ctx context.Context, ts *bayes.TrainingSet, class, path string) (int, error) {
+	var r io.Reader = os.Stdin
+	if path != "-" {
+		f, err := os.Open(path)
+		if err != nil {
+			return 0, err
+		}
+		defer f.Close()
+		r = f
+	}
+
+	c, err := findClass(ctx, ts, class)
+	if err != nil {
+		return 0, err
+	}
+
+	n := 0
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		data := strings.Fields(s.Text())
+		if len(data) == 0 {
+			continue
+		}
+		if _, err := ts.AddRecord(ctx, c, data); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, s.Err()
+}
+
+// findClass returns the class with the given name, adding it to the training
+// set if it doesn't already exist.
+func findClass(ctx context.Context, ts *bayes.TrainingSet, name string) (bayes.Class, error) {
+	cs, err := ts.Classes(ctx)
+	if err != nil {
+		return bayes.Class{}, err
+	}
+	for _, c := range cs {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return ts.AddClass(ctx, name)
+}
+
+func printTrainResults(w io.Writer, res *bayes.TrainResults) {
+	fmt.Fprintln(w, "Correct", res.Correct)
+	fmt.Fprintln(w, "Incorrect", res.Incorrect)
+	if res.Correct+res.Incorrect > 0 {
+		fmt.Fprintf(w, "Accuracy %.2f%%\n", 100*res.Accuracy())
+	}
+
+	for c, ecs := range res.Errors {
+		for _, ec := range ecs {
+			fmt.Fprintf(w, "Misclassified as %v: %d\n", c.Name(), ec.Count)
+		}
+	}
+}
+
+// classifyLines classifies each non-empty line read from r using m, and writes
+// the class name for each to w.
+func classifyLines(ctx context.Context, m *bayes.Model, r io.Reader, w io.Writer) error {
+	s := bufio.NewScanner(r)
+	for s.Scan() {
+		data := strings.Fields(s.Text())
+		if len(data) == 0 {
+			continue
+		}
+		c, err := m.Classify(ctx, data)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintln(w, c.Name())
+	}
+	return s.Err()
+}
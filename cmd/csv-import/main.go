@@ -1,8 +1,12 @@
 package main
 
 import (
+	"bufio"
+	"bytes"
+	"compress/gzip"
 	"encoding/csv"
 	"encoding/json"
+	"errors"
 	"flag"
 	"fmt"
 	"io"
@@ -10,30 +14,50 @@ import (
 	"os"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
 )
 
 var (
-	endpoint   = flag.String("endpoint", "", "endpoint `address`, uses default if not set")
-	project    = flag.String("project", "", "project `ID` to use")
-	collection = flag.String("collection", "", "collection `name` to import into (should already exist)")
-	creds      = flag.String("creds", "", "calling credentials in the form `key-id,key-secret`")
+	conn = cliutil.AddFlags(flag.CommandLine)
 
 	workers   = flag.Int("workers", 8, "use `N` workers to process data, queue and send")
 	batchSize = flag.Int("batch-size", 100, "submit records in groups of at most `N`")
 	debug     = flag.Bool("debug", false, "only print imported record, don't submit")
+
+	mappingFile = flag.String("mapping", "", "JSON `file` describing columns to rename, drop, split and template")
+
+	retries    = flag.Int("retries", 3, "retry records which fail with transient errors up to `N` times")
+	errorsFile = flag.String("errors", "errors.csv", "write rows which could not be imported to `file`, with the reason for the failure")
+
+	maxRate      = flag.Float64("max-rate", 0, "import at most `N` rows per second (0 for no limit)")
+	progressFreq = flag.Duration("progress", 5*time.Second, "log progress every `interval` (0 to disable)")
+
+	uniqueField  = flag.String("unique-field", "", "unique `field` used to identify existing records with -upsert and -skip-existing")
+	upsert       = flag.Bool("upsert", false, "update records which already exist instead of adding duplicates (requires -unique-field)")
+	skipExisting = flag.Bool("skip-existing", false, "skip records which already exist (requires -unique-field)")
+
+	createSchema = flag.Bool("create-schema", false, "infer field types from the first rows and add missing fields to the schema before importing")
+	sampleRows   = flag.Int("sample-rows", 100, "number of `rows` to sample when inferring the schema")
+	dryRun       = flag.Bool("dry-run", false, "with -create-schema, print the inferred schema and exit without changing anything")
 )
 
 func usage() {
 	fmt.Fprintf(os.Stderr, "Usage: %v [flags] file\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Use - to read from stdin. Files ending in .gz are decompressed, zstd compressed input is not supported.")
 	flag.PrintDefaults()
 }
 
 var client *sajari.Client
 
+// errs records rows which could not be imported.
+var errs *errorWriter
+
 func main() {
 	flag.Parse()
 
@@ -43,44 +67,54 @@ func main() {
 		return
 	}
 
-	var opts []sajari.Opt
-	if *endpoint != "" {
-		opts = append(opts, sajari.WithEndpoint(*endpoint))
+	if (*upsert || *skipExisting) && *uniqueField == "" {
+		log.Printf("-upsert and -skip-existing require -unique-field")
+		return
 	}
 
-	if *creds != "" {
-		credsSplit := strings.Split(*creds, ",")
-		if len(credsSplit) != 2 {
-			log.Printf("creds: expected 'id,secret', got '%v'", *creds)
-			return
-		}
-		kc := sajari.KeyCredentials(credsSplit[0], credsSplit[1])
-		opts = append(opts, sajari.WithCredentials(kc))
+	if *upsert && *skipExisting {
+		log.Printf("only one of -upsert and -skip-existing can be set")
+		return
 	}
 
 	var err error
-	client, err = sajari.New(*project, *collection, opts...)
+	client, err = conn.NewClient()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+		return
+	}
+
+	errs = &errorWriter{path: *errorsFile}
+	importErr := importCSV(file)
+
+	n, err := errs.close()
 	if err != nil {
-		fmt.Fprintf(os.Stderr, "Error dialing endpoint: %v\n", err)
+		log.Printf("error writing errors file: %v", err)
+	}
+	if n > 0 {
+		log.Printf("%d rows could not be imported, see %v", n, *errorsFile)
+	}
+	if n := atomic.LoadInt64(&skipped); n > 0 {
+		log.Printf("Skipped %d existing records", n)
 	}
 
-	if err := importCSV(file); err != nil {
-		fmt.Fprintf(os.Stderr, "Error importing data: %v\n", err)
+	if importErr != nil {
+		fmt.Fprintf(os.Stderr, "Error importing data: %v\n", importErr)
 		return
 	}
 }
 
-func sendList(list []sajari.Record) {
+func sendList(list []row) {
 	if !*debug {
-		_, err := client.AddMulti(context.Background(), list)
-		if err != nil {
-			log.Printf("error adding records: %v", err)
-			return
+		var updated []row
+		if *upsert || *skipExisting {
+			list, updated = resolveExisting(list)
 		}
+		list = append(updated, addWithRetry(list)...)
 	}
 
 	for _, d := range list {
-		b, err := json.MarshalIndent(d, "", "  ")
+		b, err := json.MarshalIndent(map[string]interface{}(d.rec), "", "  ")
 		if err != nil {
 			log.Fatal(err)
 		}
@@ -89,36 +123,74 @@ func sendList(list []sajari.Record) {
 }
 
 func importCSV(path string) error {
-	f, err := os.Open(path)
+	var m *mapping
+	if *mappingFile != "" {
+		var err error
+		m, err = loadMapping(*mappingFile)
+		if err != nil {
+			return fmt.Errorf("error loading mapping: %v", err)
+		}
+	}
+
+	f, err := openInput(path)
 	if err != nil {
 		return err
 	}
 	defer f.Close()
 
-	cr := csv.NewReader(f)
-	row, err := cr.Read()
+	var size int64
+	if fi, err := f.Stat(); err == nil && fi.Mode().IsRegular() {
+		size = fi.Size()
+	}
+
+	in := &countingReader{r: f}
+	r, err := decompress(path, in)
+	if err != nil {
+		return err
+	}
+	cr := csv.NewReader(r)
+	header, err := cr.Read()
 	if err != nil {
 		return fmt.Errorf("error reading header row: %v", err)
 	}
 
-	titles := make([]string, len(row))
-	for i, r := range row {
+	titles := make([]string, len(header))
+	for i, r := range header {
 		titles[i] = strings.Replace(strings.ToLower(r), " ", "_", -1)
 	}
+	errs.header = header
+
+	var sample [][]string
+	if *createSchema {
+		sample, err = readRows(cr, *sampleRows)
+		if err != nil {
+			return fmt.Errorf("error reading row: %v", err)
+		}
+		if err := bootstrapSchema(m, titles, sample); err != nil {
+			return fmt.Errorf("error creating schema: %v", err)
+		}
+		if *dryRun {
+			return nil
+		}
+	}
+
+	p := startProgress(in, size, *progressFreq)
+	defer p.stop()
 
 	ch := make(chan []string, 10)
 	wg := sync.WaitGroup{}
 	for i := 0; i < *workers; i++ {
 		wg.Add(1)
 		go func() {
-			batch := make([]sajari.Record, 0, *batchSize)
+			batch := make([]row, 0, *batchSize)
 			for fields := range ch {
-				m := make(map[string]interface{}, len(titles))
-				for i := range titles {
-					m[titles[i]] = fields[i]
+				rec, err := recordFromRow(m, titles, fields)
+				if err != nil {
+					errs.write(fields, err)
+					continue
 				}
 
-				batch = append(batch, sajari.Record(m))
+				batch = append(batch, row{fields: fields, rec: rec})
 				if len(batch) == *batchSize {
 					sendList(batch)
 					batch = batch[:0]
@@ -133,7 +205,15 @@ func importCSV(path string) error {
 	}
 	defer wg.Wait()
 
+	lim := newLimiter(*maxRate)
 	count := 0
+	for _, fields := range sample {
+		lim.wait()
+		ch <- fields
+		p.addRow()
+		count++
+	}
+
 	for {
 		fields, err := cr.Read()
 		if err != nil {
@@ -145,12 +225,117 @@ func importCSV(path string) error {
 			return fmt.Errorf("error reading row: %v", err)
 		}
 
+		lim.wait()
 		ch <- fields
-
+		p.addRow()
 		count++
-		if count%1000 == 0 {
-			log.Println("Done", count)
+	}
+}
+
+// openInput opens the file at path, or stdin if path is "-".
+func openInput(path string) (*os.File, error) {
+	if path == "-" {
+		return os.Stdin, nil
+	}
+	return os.Open(path)
+}
+
+// zstdMagic is the magic number at the start of a zstd frame.
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// errZstd is returned for zstd compressed input, which is not supported to avoid
+// depending on a third-party decompressor.
+var errZstd = errors.New("zstd compressed input is not supported, decompress it first (e.g. zstd -dc file.zst | csv-import -)")
+
+// decompress wraps r with a decompressing reader if required by the extension
+// of path.  Gzipped input from stdin is detected by its header.  Zstd compressed
+// input is rejected.
+func decompress(path string, r io.Reader) (io.Reader, error) {
+	switch {
+	case strings.HasSuffix(path, ".gz"):
+		return gzip.NewReader(r)
+
+	case strings.HasSuffix(path, ".zst"):
+		return nil, errZstd
+
+	case path == "-":
+		br := bufio.NewReader(r)
+		magic, _ := br.Peek(len(zstdMagic))
+		switch {
+		case len(magic) >= 2 && magic[0] == 0x1f && magic[1] == 0x8b:
+			return gzip.NewReader(br)
+		case bytes.Equal(magic, zstdMagic):
+			return nil, errZstd
+		}
+		return br, nil
+	}
+	return r, nil
+}
+
+// recordFromRow converts a row of fields with column names titles into a
+// record, applying the mapping m if it is non-nil.
+func recordFromRow(m *mapping, titles, fields []string) (sajari.Record, error) {
+	if m != nil {
+		row := make(map[string]string, len(titles))
+		for i := range titles {
+			row[titles[i]] = fields[i]
+		}
+		return m.apply(row)
+	}
+
+	rec := make(sajari.Record, len(titles))
+	for i := range titles {
+		rec[titles[i]] = fields[i]
+	}
+	return rec, nil
+}
+
+// readRows reads at most n rows from cr, stopping early at the end of input.
+func readRows(cr *csv.Reader, n int) ([][]string, error) {
+	var rows [][]string
+	for len(rows) < n {
+		fields, err := cr.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		rows = append(rows, fields)
+	}
+	return rows, nil
+}
+
+// bootstrapSchema infers a schema from the sample rows and adds any fields
+// which are missing from the collection.  If -dry-run is set then the inferred
+// schema is printed and the collection is left unchanged.
+func bootstrapSchema(m *mapping, titles []string, sample [][]string) error {
+	rs := make([]sajari.Record, 0, len(sample))
+	for _, fields := range sample {
+		rec, err := recordFromRow(m, titles, fields)
+		if err != nil {
+			return err
 		}
+		rs = append(rs, rec)
+	}
+
+	ctx := context.Background()
+	fs := inferSchema(rs)
+	missing, err := missingFields(ctx, client, fs, os.Stderr)
+	if err != nil {
+		return err
+	}
+
+	if *dryRun {
+		return printSchema(os.Stdout, fs, missing)
+	}
+
+	if len(missing) == 0 {
+		return nil
+	}
+	if err := client.Schema().Add(ctx, missing...); err != nil {
+		return err
 	}
+	log.Printf("Added %d fields to schema", len(missing))
 	return nil
 }
@@ -0,0 +1,107 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// mapping describes how to transform CSV rows into records.  Columns are
+// referred to by their normalised names (lower case, with spaces replaced
+// by _).
+//
+// A mapping file is JSON of the form:
+//
+//	{
+//	  "rename": {"product_name": "title"},
+//	  "drop": ["internal_notes"],
+//	  "split": {"tags": "|"},
+//	  "templates": {"url": "https://example.com/products/{{.id}}"}
+//	}
+//
+// Templates are evaluated against the original row, so they can refer to
+// columns which are dropped or renamed.  Columns are then dropped, split into
+// repeated values and renamed (in that order) before the template values are
+// added to the record.
+type mapping struct {
+	Rename    map[string]string `json:"rename"`
+	Drop      []string          `json:"drop"`
+	Split     map[string]string `json:"split"`
+	Templates map[string]string `json:"templates"`
+
+	drop      map[string]bool
+	templates map[string]*template.Template
+}
+
+// loadMapping reads a mapping from the JSON file at path.
+func loadMapping(path string) (*mapping, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	m := &mapping{}
+	if err := json.NewDecoder(f).Decode(m); err != nil {
+		return nil, fmt.Errorf("error decoding mapping: %v", err)
+	}
+
+	m.drop = make(map[string]bool, len(m.Drop))
+	for _, c := range m.Drop {
+		m.drop[c] = true
+	}
+
+	m.templates = make(map[string]*template.Template, len(m.Templates))
+	for field, text := range m.Templates {
+		t, err := template.New(field).Option("missingkey=error").Parse(text)
+		if err != nil {
+			return nil, fmt.Errorf("error parsing template for %q: %v", field, err)
+		}
+		m.templates[field] = t
+	}
+	return m, nil
+}
+
+// apply transforms the row into a record.
+func (m *mapping) apply(row map[string]string) (sajari.Record, error) {
+	tvs := make(map[string]string, len(m.templates))
+	for field, t := range m.templates {
+		var buf bytes.Buffer
+		if err := t.Execute(&buf, row); err != nil {
+			return nil, fmt.Errorf("error applying template for %q: %v", field, err)
+		}
+		tvs[field] = buf.String()
+	}
+
+	rec := make(sajari.Record, len(row)+len(tvs))
+	for c, v := range row {
+		if m.drop[c] {
+			continue
+		}
+
+		name := c
+		if r, ok := m.Rename[c]; ok {
+			name = r
+		}
+
+		if sep, ok := m.Split[c]; ok {
+			vs := []string{}
+			if v != "" {
+				vs = strings.Split(v, sep)
+			}
+			rec[name] = vs
+			continue
+		}
+		rec[name] = v
+	}
+
+	for field, v := range tvs {
+		rec[field] = v
+	}
+	return rec, nil
+}
@@ -0,0 +1,138 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sync/atomic"
+	"time"
+)
+
+// countingReader counts the bytes read from r.
+type countingReader struct {
+	r io.Reader
+	n int64 // accessed atomically
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.r.Read(p)
+	atomic.AddInt64(&c.n, int64(n))
+	return n, err
+}
+
+func (c *countingReader) count() int64 {
+	return atomic.LoadInt64(&c.n)
+}
+
+// progress periodically logs the number of rows and bytes processed, the
+// import rate and an estimate of the time remaining.
+type progress struct {
+	cr    *countingReader
+	size  int64 // total input size, or 0 if unknown
+	start time.Time
+	rows  int64 // accessed atomically
+
+	done chan struct{}
+}
+
+// startProgress starts logging progress every interval.  The total size is used to
+// estimate the time remaining, and is ignored if not positive.
+func startProgress(cr *countingReader, size int64, interval time.Duration) *progress {
+	p := &progress{
+		cr:    cr,
+		size:  size,
+		start: time.Now(),
+		done:  make(chan struct{}),
+	}
+
+	if interval > 0 {
+		go func() {
+			t := time.NewTicker(interval)
+			defer t.Stop()
+			for {
+				select {
+				case <-t.C:
+					log.Println(p)
+				case <-p.done:
+					return
+				}
+			}
+		}()
+	}
+	return p
+}
+
+// addRow records that a row has been read.
+func (p *progress) addRow() {
+	atomic.AddInt64(&p.rows, 1)
+}
+
+// stop stops logging progress.
+func (p *progress) stop() {
+	close(p.done)
+}
+
+// String implements fmt.Stringer.
+func (p *progress) String() string {
+	rows := atomic.LoadInt64(&p.rows)
+	n := p.cr.count()
+	elapsed := time.Since(p.start)
+
+	s := fmt.Sprintf("%d rows, %v", rows, formatBytes(n))
+	if p.size > 0 {
+		s += fmt.Sprintf(" (%.1f%%)", 100*float64(n)/float64(p.size))
+	}
+	if elapsed > 0 {
+		s += fmt.Sprintf(", %.0f rows/s", float64(rows)/elapsed.Seconds())
+	}
+	if p.size > 0 && n > 0 && n < p.size {
+		eta := time.Duration(float64(elapsed) * float64(p.size-n) / float64(n))
+		s += fmt.Sprintf(", ETA %v", eta-eta%time.Second)
+	}
+	return s
+}
+
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
+
+// limiter paces events so that they occur at most rate times per second.
+type limiter struct {
+	interval time.Duration
+	next     time.Time
+}
+
+// newLimiter returns a limiter which allows rate events per second.  If rate is
+// not positive then events are not limited.
+func newLimiter(rate float64) *limiter {
+	if rate <= 0 {
+		return &limiter{}
+	}
+	return &limiter{
+		interval: time.Duration(float64(time.Second) / rate),
+	}
+}
+
+// wait blocks until the next event is allowed.
+func (l *limiter) wait() {
+	if l.interval == 0 {
+		return
+	}
+
+	now := time.Now()
+	if l.next.After(now) {
+		time.Sleep(l.next.Sub(now))
+	} else {
+		l.next = now
+	}
+	l.next = l.next.Add(l.interval)
+}
@@ -0,0 +1,134 @@
+package main
+
+import (
+	"encoding/csv"
+	"log"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/retryutil"
+)
+
+// row is a record along with the CSV fields it was created from.
+type row struct {
+	fields []string
+	rec    sajari.Record
+}
+
+// addWithRetry adds the records in rows, retrying those which fail with
+// transient errors using exponential backoff.  Rows which fail permanently
+// are written to errs.  Returns the rows which were added.
+func addWithRetry(rows []row) []row {
+	var added []row
+	pending := rows
+	for attempt := 0; ; attempt++ {
+		rs := make([]sajari.Record, 0, len(pending))
+		for _, r := range pending {
+			rs = append(rs, r.rec)
+		}
+
+		_, err := client.AddMulti(context.Background(), rs)
+		if err == nil {
+			return append(added, pending...)
+		}
+
+		me, isMulti := err.(sajari.MultiError)
+		if isMulti && len(me) != len(pending) {
+			isMulti = false
+		}
+
+		var retry []row
+		unsent := false
+		for i, r := range pending {
+			rerr := err
+			if isMulti {
+				rerr = me[i]
+			}
+
+			switch {
+			case rerr == nil:
+				added = append(added, r)
+
+			case rerr == sajari.ErrNotAdded:
+				retry = append(retry, r)
+				unsent = true
+
+			case attempt < *retries && retryutil.IsTransient(rerr):
+				retry = append(retry, r)
+
+			default:
+				errs.write(r.fields, rerr)
+			}
+		}
+
+		if len(retry) == 0 {
+			return added
+		}
+		pending = retry
+		if unsent {
+			// Nothing was added because of invalid rows in the batch, which have
+			// now been removed: resend the rest without using up a retry.
+			attempt--
+			continue
+		}
+		time.Sleep(retryutil.Backoff(attempt))
+	}
+}
+
+// errorWriter writes failed rows to a CSV file, with the reason for the failure
+// in an additional column.  The file is only created when the first row is
+// written.
+type errorWriter struct {
+	path   string
+	header []string
+
+	mu sync.Mutex
+	f  *os.File
+	w  *csv.Writer
+	n  int
+}
+
+// write records that the row with the given fields failed with err.
+func (e *errorWriter) write(fields []string, err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	e.n++
+	if e.path == "" {
+		return
+	}
+
+	if e.w == nil {
+		f, ferr := os.Create(e.path)
+		if ferr != nil {
+			// Don't try again, the failure count is still reported.
+			e.path = ""
+			log.Printf("error creating errors file: %v", ferr)
+			return
+		}
+		e.f = f
+		e.w = csv.NewWriter(f)
+		e.w.Write(append(e.header, "error"))
+	}
+	e.w.Write(append(fields[:len(fields):len(fields)], err.Error()))
+}
+
+// close flushes and closes the errors file, returning the number of failed rows.
+func (e *errorWriter) close() (int, error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.w == nil {
+		return e.n, nil
+	}
+	e.w.Flush()
+	if err := e.w.Error(); err != nil {
+		e.f.Close()
+		return e.n, err
+	}
+	return e.n, e.f.Close()
+}
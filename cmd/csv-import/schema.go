@@ -0,0 +1,143 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"text/tabwriter"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// inferSchema infers fields from the sample records rs.  A field is given the
+// most specific type (integer, float, boolean, string) which all its non-empty
+// values satisfy, and is repeated if any of its values are lists.
+func inferSchema(rs []sajari.Record) []sajari.Field {
+	types := make(map[string]sajari.Type)
+	repeated := make(map[string]bool)
+	for _, r := range rs {
+		for k, v := range r {
+			var vs []string
+			switch v := v.(type) {
+			case string:
+				vs = []string{v}
+
+			case []string:
+				repeated[k] = true
+				vs = v
+			}
+
+			for _, x := range vs {
+				if x == "" {
+					continue
+				}
+				types[k] = widenType(types[k], x)
+			}
+			if _, ok := types[k]; !ok {
+				types[k] = ""
+			}
+		}
+	}
+
+	names := make([]string, 0, len(types))
+	for k := range types {
+		names = append(names, k)
+	}
+	sort.Strings(names)
+
+	fs := make([]sajari.Field, 0, len(names))
+	for _, k := range names {
+		t := types[k]
+		if t == "" {
+			t = sajari.TypeString
+		}
+		fs = append(fs, sajari.Field{
+			Name:     k,
+			Type:     t,
+			Repeated: repeated[k],
+		})
+	}
+	return fs
+}
+
+// widenType returns the most specific type which can represent both values of
+// type t and the value v.  The empty type is used when no values have been seen.
+func widenType(t sajari.Type, v string) sajari.Type {
+	_, intErr := strconv.ParseInt(v, 10, 64)
+	_, floatErr := strconv.ParseFloat(v, 64)
+	_, boolErr := strconv.ParseBool(v)
+
+	switch t {
+	case "", sajari.TypeInteger:
+		if intErr == nil {
+			return sajari.TypeInteger
+		}
+		if floatErr == nil {
+			return sajari.TypeFloat
+		}
+		if t == "" && boolErr == nil {
+			return sajari.TypeBoolean
+		}
+
+	case sajari.TypeFloat:
+		if floatErr == nil {
+			return sajari.TypeFloat
+		}
+
+	case sajari.TypeBoolean:
+		if boolErr == nil {
+			return sajari.TypeBoolean
+		}
+	}
+	return sajari.TypeString
+}
+
+// missingFields returns the fields in fs which are not in the collection
+// schema.  Fields which exist with a different type are reported to w.
+func missingFields(ctx context.Context, client *sajari.Client, fs []sajari.Field, w io.Writer) ([]sajari.Field, error) {
+	existing, err := client.Schema().Fields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	byName := make(map[string]sajari.Field, len(existing))
+	for _, f := range existing {
+		byName[f.Name] = f
+	}
+
+	var out []sajari.Field
+	for _, f := range fs {
+		ef, ok := byName[f.Name]
+		if !ok {
+			out = append(out, f)
+			continue
+		}
+		if ef.Type != f.Type || ef.Repeated != f.Repeated {
+			fmt.Fprintf(w, "warning: field %q exists with type %v (repeated: %v), inferred %v (repeated: %v)\n", f.Name, ef.Type, ef.Repeated, f.Type, f.Repeated)
+		}
+	}
+	return out, nil
+}
+
+// printSchema writes the fields fs to w as a table, marking those in missing
+// as new.
+func printSchema(w io.Writer, fs, missing []sajari.Field) error {
+	isNew := make(map[string]bool, len(missing))
+	for _, f := range missing {
+		isNew[f.Name] = true
+	}
+
+	tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(tw, "NAME\tTYPE\tREPEATED\tSTATUS")
+	for _, f := range fs {
+		status := "exists"
+		if isNew[f.Name] {
+			status = "new"
+		}
+		fmt.Fprintf(tw, "%v\t%v\t%v\t%v\n", f.Name, f.Type, f.Repeated, status)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,97 @@
+package main
+
+import (
+	"fmt"
+	"sync/atomic"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// skipped counts rows which were not imported because they already exist.
+var skipped int64
+
+// resolveExisting checks which rows already exist in the collection, using the
+// value of -unique-field as the key.  Existing rows are updated if -upsert is
+// set, or skipped if -skip-existing is set.  Returns the rows which should be
+// added and the rows which were updated.  Rows which could not be checked or
+// updated are written to errs.
+func resolveExisting(rows []row) (toAdd, updated []row) {
+	ks := make([]*sajari.Key, 0, len(rows))
+	keyed := make([]row, 0, len(rows))
+	for _, r := range rows {
+		v, ok := r.rec[*uniqueField]
+		if !ok || v == "" {
+			errs.write(r.fields, fmt.Errorf("missing value for unique field %q", *uniqueField))
+			continue
+		}
+		ks = append(ks, sajari.NewKey(*uniqueField, v))
+		keyed = append(keyed, r)
+	}
+	if len(keyed) == 0 {
+		return nil, nil
+	}
+
+	ctx := context.Background()
+	exists, err := client.ExistsMulti(ctx, ks)
+	if err != nil {
+		for i, r := range keyed {
+			rerr := err
+			if me, ok := err.(sajari.MultiError); ok && len(me) == len(keyed) && me[i] != nil {
+				rerr = me[i]
+			}
+			errs.write(r.fields, fmt.Errorf("error checking for existing record: %v", rerr))
+		}
+		return nil, nil
+	}
+
+	var rms []sajari.RecordMutation
+	var existing []row
+	for i, r := range keyed {
+		if !exists[i] {
+			toAdd = append(toAdd, r)
+			continue
+		}
+
+		if *skipExisting {
+			atomic.AddInt64(&skipped, 1)
+			continue
+		}
+
+		values := make(map[string]interface{}, len(r.rec))
+		for k, v := range r.rec {
+			if k != *uniqueField {
+				values[k] = v
+			}
+		}
+		rms = append(rms, sajari.RecordMutation{
+			Key:            ks[i],
+			FieldMutations: sajari.SetFields(values),
+		})
+		existing = append(existing, r)
+	}
+
+	if len(rms) == 0 {
+		return toAdd, nil
+	}
+
+	err = client.MutateMulti(ctx, rms...)
+	if err == nil {
+		return toAdd, existing
+	}
+
+	me, isMulti := err.(sajari.MultiError)
+	for i, r := range existing {
+		rerr := err
+		if isMulti && len(me) == len(existing) {
+			rerr = me[i]
+		}
+		if rerr == nil {
+			updated = append(updated, r)
+			continue
+		}
+		errs.write(r.fields, fmt.Errorf("error updating existing record: %v", rerr))
+	}
+	return toAdd, updated
+}
@@ -0,0 +1,112 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+
+	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
+)
+
+var (
+	conn = cliutil.AddFlags(flag.CommandLine)
+
+	key   = flag.String("key", "", "`field:value` pair which identifies the record")
+	q     = flag.String("q", "", "query `text` the interaction is for")
+	count = flag.Int("count", 1, "number of interactions to apply")
+	score = flag.Float64("score", 0, "`score` to apply to each interaction (overridden by -pos and -neg)")
+	pos   = flag.Bool("pos", false, "apply a positive interaction (score 1)")
+	neg   = flag.Bool("neg", false, "apply a negative interaction (score -1)")
+
+	analyse = flag.Bool("analyse", false, "print the record terms which match the query and exit without applying any interactions")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %v [flags] -key field:value -q text (-pos|-neg|-score N)\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Applies interactions to the record terms which match the query text, as if a user")
+	fmt.Fprintln(os.Stderr, "had interacted with the record after searching for it.")
+	fmt.Fprintln(os.Stderr)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	fieldValue := strings.SplitN(*key, ":", 2)
+	if len(fieldValue) != 2 {
+		log.Println("key: expected field:value")
+		return
+	}
+
+	if *q == "" {
+		log.Println("q: cannot be empty")
+		return
+	}
+
+	if *pos && *neg {
+		log.Println("only one of -pos and -neg can be set")
+		return
+	}
+
+	s := float32(*score)
+	switch {
+	case *pos:
+		s = 1
+	case *neg:
+		s = -1
+	}
+
+	if s == 0 && !*analyse {
+		log.Println("no interaction given, use -pos, -neg or -score")
+		return
+	}
+
+	client, err := conn.NewClient()
+	if err != nil {
+		log.Printf("error creating client: %v", err)
+		return
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("error closing Client: %v", err)
+		}
+	}()
+
+	ctx := context.Background()
+	k := sajari.NewKey(fieldValue[0], fieldValue[1])
+	r := sajari.Request{
+		IndexQuery: sajari.IndexQuery{
+			Text: *q,
+		},
+	}
+
+	terms, err := client.Query().Analyse(ctx, k, r)
+	if err != nil {
+		log.Printf("error analysing record: Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
+		return
+	}
+	fmt.Println("Terms:", strings.Join(terms, " "))
+
+	if *analyse {
+		return
+	}
+
+	if len(terms) == 0 {
+		log.Println("no record terms match the query, nothing to learn")
+		return
+	}
+
+	if err := client.Learn(ctx, k, r, *count, s); err != nil {
+		log.Printf("error applying interaction: Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
+		return
+	}
+	fmt.Printf("Applied %d interactions with score %v\n", *count, s)
+}
@@ -5,48 +5,65 @@ import (
 	"flag"
 	"fmt"
 	"log"
+	"os"
+	"strconv"
 	"strings"
+	"time"
 
 	"golang.org/x/net/context"
 
 	"google.golang.org/grpc"
 
 	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/benchutil"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
 )
 
 var (
-	endpoint   = flag.String("endpoint", "", "endpoint `address`, uses default if not set")
-	project    = flag.String("project", "", "project `name` to query")
-	collection = flag.String("collection", "", "collection `name` to query")
-	creds      = flag.String("creds", "", "calling credentials `key-id,key-secret`")
+	conn = cliutil.AddFlags(flag.CommandLine)
 
 	name   = flag.String("name", "website", "`algorithm` to run")
 	values = flag.String("values", "", "`key:value` pairs, comma-seperated")
 
+	page           = flag.Int("page", 0, "`page` of results to return, sets the page value")
+	resultsPerPage = flag.Int("results-per-page", 0, "`number` of results per page, sets the resultsPerPage value")
+
+	count       = flag.Int("count", 1, "run the pipeline `N` times and record stats, results are not printed if N > 1")
+	concurrency = flag.Int("concurrency", 1, "run queries (see -count) using `N` concurrent workers")
+
+	valuesFile = flag.String("values-file", "", "`path` to JSON object of values, merged with -values and -value")
+
 	tracking      = flag.String("tracking", "", "tokens to create for each result, either `CLICK or POS_NEG`")
 	trackingField = flag.String("tracking-field", "", "unique field to use in tracking (must be returned in result set)")
 	trackingData  = flag.String("tracking-data", "", "`key:value` pairs, comma-seperated")
 )
 
+var valueFlags = valueList{}
+
+func init() {
+	flag.Var(valueFlags, "value", "`key=value` pair (can be repeated), values may contain any characters")
+}
+
 func main() {
 	flag.Parse()
 
-	var opts []sajari.Opt
-	if *endpoint != "" {
-		opts = append(opts, sajari.WithEndpoint(*endpoint))
+	if *concurrency < 1 {
+		log.Printf("-concurrency must be at least 1")
+		return
 	}
 
-	if *creds != "" {
-		credsSplit := strings.Split(*creds, ",")
-		if len(credsSplit) != 2 {
-			log.Printf("creds: expected 'id,secret', got '%v'", *creds)
+	input := make(map[string]string)
+	if *valuesFile != "" {
+		vs, err := readValuesFile(*valuesFile)
+		if err != nil {
+			log.Printf("error reading -values-file: %v", err)
 			return
 		}
-		kc := sajari.KeyCredentials(credsSplit[0], credsSplit[1])
-		opts = append(opts, sajari.WithCredentials(kc))
+		for k, v := range vs {
+			input[k] = v
+		}
 	}
 
-	input := make(map[string]string)
 	if *values != "" {
 		pairs := strings.Split(*values, ",")
 		for _, pair := range pairs {
@@ -59,6 +76,17 @@ func main() {
 		}
 	}
 
+	for k, v := range valueFlags {
+		input[k] = v
+	}
+
+	if *page > 0 {
+		input["page"] = strconv.Itoa(*page)
+	}
+	if *resultsPerPage > 0 {
+		input["resultsPerPage"] = strconv.Itoa(*resultsPerPage)
+	}
+
 	tr := sajari.Tracking{}
 	if *tracking != "" {
 		if *trackingField == "" {
@@ -66,19 +94,22 @@ func main() {
 			return
 		}
 
+		var err error
 		switch *tracking {
 		case "CLICK":
-			tr.Type = sajari.TrackingClick
+			tr, err = sajari.ClickTracking(*trackingField, nil)
 
 		case "POS_NEG":
-			tr.Type = sajari.TrackingPosNeg
+			tr, err = sajari.PosNegTracking(*trackingField, nil)
 
 		default:
 			log.Printf("unknown tracking type: %q", *tracking)
 			return
 		}
-
-		tr.Field = *trackingField
+		if err != nil {
+			log.Printf("%v", err)
+			return
+		}
 	}
 
 	if *trackingData != "" {
@@ -95,9 +126,9 @@ func main() {
 		tr.Data = m
 	}
 
-	client, err := sajari.New(*project, *collection, opts...)
+	client, err := conn.NewClient()
 	if err != nil {
-		log.Printf("error from sajari.New(): %v", err)
+		log.Printf("error creating client: %v", err)
 		return
 	}
 	defer func() {
@@ -106,8 +137,23 @@ func main() {
 		}
 	}()
 
+	p := client.Pipeline(*name)
+	if *count > 1 {
+		s := &benchutil.Stats{}
+		start := time.Now()
+		benchutil.Run(*count, *concurrency, s, func() (*sajari.Results, error) {
+			resp, _, err := p.Search(context.Background(), input, tr)
+			if err != nil {
+				log.Printf("Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
+			}
+			return resp, err
+		})
+		s.Print(os.Stdout, time.Since(start))
+		return
+	}
+
 	ctx := context.Background()
-	resp, _, err := client.Pipeline(*name).Search(ctx, input, tr)
+	resp, outValues, err := p.Search(ctx, input, tr)
 	if err != nil {
 		log.Printf("Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
 		return
@@ -121,6 +167,15 @@ func main() {
 		fmt.Println(string(b))
 	}
 
+	if len(outValues) > 0 {
+		b, err := json.MarshalIndent(outValues, "", "  ")
+		if err != nil {
+			log.Printf("could not write out values (%v): %v", outValues, err)
+		}
+		fmt.Println("Values:")
+		fmt.Println(string(b))
+	}
+
 	fmt.Println("Total Results", len(resp.Results))
 	fmt.Println("Reads", resp.Reads)
 	fmt.Println("Time", resp.Time)
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+)
+
+// valueList is a flag.Value which collects key=value pairs from repeated
+// flags.  Only the first = separates the key from the value, so values can
+// contain any other characters.
+type valueList map[string]string
+
+func (vs valueList) String() string {
+	pairs := make([]string, 0, len(vs))
+	for k, v := range vs {
+		pairs = append(pairs, k+"="+v)
+	}
+	return strings.Join(pairs, " ")
+}
+
+func (vs valueList) Set(s string) error {
+	kv := strings.SplitN(s, "=", 2)
+	if len(kv) != 2 {
+		return fmt.Errorf("expected key=value, got %q", s)
+	}
+	vs[kv[0]] = kv[1]
+	return nil
+}
+
+// readValuesFile reads pipeline values from a JSON object in the file at path.
+// Non-string values are converted to strings, with numbers kept as written.
+func readValuesFile(path string) (map[string]string, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	m := make(map[string]interface{})
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	if err := dec.Decode(&m); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON values: %v", err)
+	}
+
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		switch v := v.(type) {
+		case string:
+			out[k] = v
+
+		case nil:
+			out[k] = ""
+
+		case json.Number:
+			out[k] = v.String()
+
+		case map[string]interface{}, []interface{}:
+			return nil, fmt.Errorf("value for %q must be a string, number or boolean", k)
+
+		default:
+			out[k] = fmt.Sprintf("%v", v)
+		}
+	}
+	return out, nil
+}
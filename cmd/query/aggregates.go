@@ -0,0 +1,88 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// parseAggregates parses a comma separated list of aggregate definitions.  Each definition
+// is either type:field:name, where type is one of count, min, max, avg or sum, or
+// bucket:name:buckets where buckets is a semi-colon separated list of bucket=filter.
+//
+// Bucket filters are one or more filter expressions (see sajari.ParseFilter) joined by &, for
+// example:
+//
+//	bucket:price:cheap=price<10;mid=price>=10&price<100;expensive=price>=100
+func parseAggregates(s string) (map[string]sajari.Aggregate, error) {
+	out := make(map[string]sajari.Aggregate)
+	for _, aggregate := range strings.Split(s, ",") {
+		items := strings.SplitN(aggregate, ":", 3)
+		if len(items) != 3 {
+			return nil, fmt.Errorf("aggregates: invalid aggregate %q (should be of the form type:field:name or bucket:name:buckets)", aggregate)
+		}
+
+		var a sajari.Aggregate
+		name := items[2]
+		switch items[0] {
+		case "count":
+			a = sajari.CountAggregate(items[1])
+
+		case "min":
+			a = sajari.MinAggregate(items[1])
+
+		case "max":
+			a = sajari.MaxAggregate(items[1])
+
+		case "avg":
+			a = sajari.AvgAggregate(items[1])
+
+		case "sum":
+			a = sajari.SumAggregate(items[1])
+
+		case "bucket":
+			bs, err := parseBuckets(items[2])
+			if err != nil {
+				return nil, fmt.Errorf("aggregates: %v", err)
+			}
+			a = sajari.BucketAggregate(bs...)
+			name = items[1]
+
+		default:
+			return nil, fmt.Errorf("aggregates: invalid aggregate type %q", items[0])
+		}
+		out[name] = a
+	}
+	return out, nil
+}
+
+// parseBuckets parses a semi-colon separated list of name=filter bucket definitions.
+func parseBuckets(s string) ([]sajari.Bucket, error) {
+	var bs []sajari.Bucket
+	for _, def := range strings.Split(s, ";") {
+		items := strings.SplitN(def, "=", 2)
+		if len(items) != 2 || items[0] == "" {
+			return nil, fmt.Errorf("invalid bucket %q (should be of the form name=filter)", def)
+		}
+
+		var fs []sajari.Filter
+		for _, expr := range strings.Split(items[1], "&") {
+			f, err := sajari.ParseFilter(expr)
+			if err != nil {
+				return nil, fmt.Errorf("bucket %q: %v", items[0], err)
+			}
+			fs = append(fs, f)
+		}
+
+		f := fs[0]
+		if len(fs) > 1 {
+			f = sajari.AllFilters(fs...)
+		}
+		bs = append(bs, sajari.Bucket{
+			Name:   items[0],
+			Filter: f,
+		})
+	}
+	return bs, nil
+}
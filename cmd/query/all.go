@@ -0,0 +1,42 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// fetchAll pages through all results for the request r (starting at r.Offset, r.Limit
+// results at a time), writing the values of each result to w as newline delimited JSON.
+// Returns the number of results written.
+func fetchAll(client *sajari.Client, r *sajari.Request, w io.Writer) (int, error) {
+	if r.Limit <= 0 {
+		return 0, fmt.Errorf("limit must be greater than 0 to fetch all results")
+	}
+
+	req := *r
+	enc := json.NewEncoder(w)
+	n := 0
+	for {
+		resp, err := client.Query().Search(context.Background(), &req)
+		if err != nil {
+			return n, err
+		}
+
+		for _, result := range resp.Results {
+			if err := enc.Encode(result.Values); err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		req.Offset += len(resp.Results)
+		if len(resp.Results) == 0 || req.Offset >= resp.TotalResults {
+			return n, nil
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// parseFieldBoosts parses a comma separated list of field boost definitions of
+// the given kind (see parseFieldBoost).
+func parseFieldBoosts(kind, s string) ([]sajari.FieldBoost, error) {
+	var out []sajari.FieldBoost
+	for _, def := range strings.Split(s, ",") {
+		b, err := parseFieldBoost(kind, def)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, b)
+	}
+	return out, nil
+}
+
+// parseFieldBoost parses a field boost definition of the given kind:
+//
+//	filter:    field op value:boost       e.g. category=news:2
+//	interval:  field:point=value;...      e.g. price:0=1;100=0.5;1000=0
+//	element:   field:elt;...              e.g. tags:go;grpc
+//	text:      field:text                 e.g. title:search engine
+func parseFieldBoost(kind, def string) (sajari.FieldBoost, error) {
+	switch kind {
+	case "filter":
+		i := strings.LastIndex(def, ":")
+		if i < 0 {
+			return nil, fmt.Errorf("filter boost: expected filter:value, got %q", def)
+		}
+		f, err := sajari.ParseFilter(def[:i])
+		if err != nil {
+			return nil, fmt.Errorf("filter boost: %v", err)
+		}
+		value, err := strconv.ParseFloat(def[i+1:], 64)
+		if err != nil {
+			return nil, fmt.Errorf("filter boost: error parsing boost value %q: %v", def[i+1:], err)
+		}
+		return sajari.FilterFieldBoost(f, value), nil
+
+	case "interval":
+		items := strings.SplitN(def, ":", 2)
+		if len(items) != 2 {
+			return nil, fmt.Errorf("interval boost: expected field:point=value;..., got %q", def)
+		}
+		var points []sajari.IntervalPoint
+		for _, pv := range strings.Split(items[1], ";") {
+			x := strings.SplitN(pv, "=", 2)
+			if len(x) != 2 {
+				return nil, fmt.Errorf("interval boost: expected point=value, got %q", pv)
+			}
+			point, err := strconv.ParseFloat(x[0], 64)
+			if err != nil {
+				return nil, fmt.Errorf("interval boost: error parsing point %q: %v", x[0], err)
+			}
+			value, err := strconv.ParseFloat(x[1], 64)
+			if err != nil {
+				return nil, fmt.Errorf("interval boost: error parsing value %q: %v", x[1], err)
+			}
+			points = append(points, sajari.IntervalPoint{
+				Point: point,
+				Value: value,
+			})
+		}
+		return sajari.IntervalFieldBoost(items[0], points...), nil
+
+	case "element":
+		items := strings.SplitN(def, ":", 2)
+		if len(items) != 2 {
+			return nil, fmt.Errorf("element boost: expected field:elt;..., got %q", def)
+		}
+		return sajari.ElementFieldBoost(items[0], strings.Split(items[1], ";")), nil
+
+	case "text":
+		items := strings.SplitN(def, ":", 2)
+		if len(items) != 2 {
+			return nil, fmt.Errorf("text boost: expected field:text, got %q", def)
+		}
+		return sajari.TextFieldBoost(items[0], items[1]), nil
+	}
+	return nil, fmt.Errorf("unknown boost type %q (should be one of filter, interval, element or text)", kind)
+}
+
+// parseFeatureBoosts parses a comma separated list of feature boosts of the form
+// portion:type:definition, where type and definition are as for parseFieldBoost.
+func parseFeatureBoosts(s string) ([]sajari.FeatureFieldBoost, error) {
+	var out []sajari.FeatureFieldBoost
+	for _, def := range strings.Split(s, ",") {
+		items := strings.SplitN(def, ":", 3)
+		if len(items) != 3 {
+			return nil, fmt.Errorf("feature boost: expected portion:type:definition, got %q", def)
+		}
+		portion, err := strconv.ParseFloat(items[0], 64)
+		if err != nil {
+			return nil, fmt.Errorf("feature boost: error parsing portion %q: %v", items[0], err)
+		}
+		b, err := parseFieldBoost(items[1], items[2])
+		if err != nil {
+			return nil, fmt.Errorf("feature boost: %v", err)
+		}
+		out = append(out, sajari.NewFeatureFieldBoost(b, portion))
+	}
+	return out, nil
+}
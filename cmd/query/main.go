@@ -4,7 +4,11 @@ import (
 	"encoding/json"
 	"flag"
 	"fmt"
+	"io"
+	"io/ioutil"
 	"log"
+	"os"
+	"regexp"
 	"strconv"
 	"strings"
 	"time"
@@ -14,47 +18,205 @@ import (
 	"google.golang.org/grpc"
 
 	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/benchutil"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
 )
 
 var (
-	endpoint   = flag.String("endpoint", "", "endpoint `address`, uses default if not set")
-	project    = flag.String("project", "", "project `name` to query")
-	collection = flag.String("collection", "", "collection `name` to query")
-	creds      = flag.String("creds", "", "calling credentials `key-id,key-secret`")
+	conn = cliutil.AddFlags(flag.CommandLine)
 
 	text          = flag.String("text", "", "body `text` to search for")
 	limit         = flag.Int("limit", 10, "fetch `N` results")
 	offset        = flag.Int("offset", 0, "fetch results starting with the `N`th")
-	fields        = flag.String("fields", "", "comma separated list of `field names`")
-	sort          = flag.String("sort", "", "comma seperated `list` of [-]field")
-	filter        = flag.String("filter", "", "comma seperated `list` of field[ ]op:value")
+	fields        = flag.String("fields", "", "comma separated list of `field names`, use \"field AS alias\" to rename a field")
+	sortBy        = flag.String("sort", "", "comma seperated `list` of [-]field, e.g. -published_at,title")
+	filter        = flag.String("filter", "", "filter `expression`, e.g. \"(type = article AND published >= 1500000000) OR title ~ sajari\"")
 	indexBoost    = flag.String("indexboost", "", "comma seperated `list` of field:value")
+	filterBoost   = flag.String("filter-boost", "", "comma seperated `list` of filter:boost, e.g. category=news:2")
+	intervalBoost = flag.String("interval-boost", "", "comma seperated `list` of field:point=value;..., e.g. price:0=1;100=0")
+	elementBoost  = flag.String("element-boost", "", "comma seperated `list` of field:elt;..., e.g. tags:go;grpc")
+	textBoost     = flag.String("text-boost", "", "comma seperated `list` of field:text")
+	featureBoost  = flag.String("feature-boost", "", "comma seperated `list` of portion:type:definition where type is filter, interval, element or text")
 	count         = flag.Int("count", 1, "run the query `N` times and record stats")
 	tracking      = flag.String("tracking", "", "tokens to create for each result, either `CLICK or POS_NEG`")
 	trackingField = flag.String("tracking-field", "", "unique field to use in tracking (must be returned in result set)")
 	trackingData  = flag.String("tracking-data", "", "`key:value` pairs, comma-seperated")
+	tokensOut     = flag.String("tokens-out", "", "write tracking tokens to `file`, one per line as key<TAB>type<TAB>token (not with -i, -all or -concurrency)")
 	transforms    = flag.String("transforms", "", "comma seperated `list` of transform identifiers")
-	aggregates    = flag.String("aggregates", "", "comma seperated `list` of `aggregate-type:field")
+	aggregates    = flag.String("aggregates", "", "comma seperated `list` of type:field:name (type is count, min, max, avg or sum) or bucket:name:bucket=filter;...")
+	request       = flag.String("request", "", "`path` to a JSON encoded request to run (other request flags are ignored)")
+	printRequest  = flag.Bool("print-request", false, "print the JSON encoded request and exit (for use with -request)")
+	output        = flag.String("output", "json", "output `format` for results: json, ndjson, csv or table")
+	quiet         = flag.Bool("quiet", false, "only print query stats")
+	interactive   = flag.Bool("i", false, "run interactively, reading queries and commands from stdin")
+	all           = flag.Bool("all", false, "fetch all results, -limit at a time, and write their values to stdout as newline delimited JSON")
+	concurrency   = flag.Int("concurrency", 1, "run queries (see -count) using `N` concurrent workers, results are not printed if N > 1")
 )
 
 func main() {
 	flag.Parse()
 
-	var opts []sajari.Opt
-	if *endpoint != "" {
-		opts = append(opts, sajari.WithEndpoint(*endpoint))
+	if *concurrency < 1 {
+		log.Printf("-concurrency must be at least 1")
+		return
+	}
+
+	if *tokensOut != "" && (*interactive || *all || *concurrency > 1) {
+		log.Printf("-tokens-out can't be used with -i, -all or -concurrency > 1")
+		return
+	}
+
+	var r *sajari.Request
+	if *request != "" {
+		b, err := ioutil.ReadFile(*request)
+		if err != nil {
+			log.Printf("request: error reading file: %v", err)
+			return
+		}
+		r, err = sajari.UnmarshalRequestJSON(b)
+		if err != nil {
+			log.Printf("request: error parsing %v: %v", *request, err)
+			return
+		}
+	} else {
+		var err error
+		r, err = requestFromFlags()
+		if err != nil {
+			log.Println(err)
+			return
+		}
+	}
+
+	if *printRequest {
+		b, err := sajari.MarshalRequestJSON(*r)
+		if err != nil {
+			log.Printf("error encoding request: %v", err)
+			return
+		}
+		fmt.Println(string(b))
+		return
+	}
+
+	client, err := conn.NewClient()
+	if err != nil {
+		log.Printf("error creating client: %v", err)
+		return
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("error closing Client: %v", err)
+		}
+	}()
+
+	rw, err := newResultWriter(*output, os.Stdout, r.Fields)
+	if err != nil {
+		log.Println(err)
+		return
 	}
 
-	if *creds != "" {
-		credsSplit := strings.Split(*creds, ",")
-		if len(credsSplit) != 2 {
-			log.Printf("creds: expected 'id,secret', got '%v'", *creds)
+	if *interactive {
+		runREPL(client, r, rw)
+		return
+	}
+
+	if *all {
+		n, err := fetchAll(client, r, os.Stdout)
+		if err != nil {
+			log.Printf("Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
 			return
 		}
-		kc := sajari.KeyCredentials(credsSplit[0], credsSplit[1])
-		opts = append(opts, sajari.WithCredentials(kc))
+		log.Printf("Fetched %d results", n)
+		return
+	}
+
+	// Only pretty-printed JSON output is intended to be read directly, other formats
+	// are written to be piped elsewhere so aggregates and stats go to stderr.
+	var info io.Writer = os.Stderr
+	if *output == "json" || *quiet {
+		info = os.Stdout
+	}
+
+	var tw *tokenWriter
+	if *tokensOut != "" {
+		if r.Tracking.Type == sajari.TrackingNone {
+			log.Printf("-tokens-out requires tracking, see -tracking")
+			return
+		}
+
+		var err error
+		tw, err = newTokenWriter(*tokensOut, r.Tracking.Field)
+		if err != nil {
+			log.Printf("error creating tokens file: %v", err)
+			return
+		}
+		defer func() {
+			if err := tw.Close(); err != nil {
+				log.Printf("error writing tokens file: %v", err)
+			}
+		}()
 	}
 
+	s := &benchutil.Stats{}
+	start := time.Now()
+
+	if *concurrency > 1 {
+		benchutil.Run(*count, *concurrency, s, func() (*sajari.Results, error) {
+			resp, err := client.Query().Search(context.Background(), r)
+			if err != nil {
+				log.Printf("Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
+			}
+			return resp, err
+		})
+		s.Print(info, time.Since(start))
+		return
+	}
+
+	for i := 0; i < *count; i++ {
+		ctx := context.Background()
+		qstart := time.Now()
+		resp, err := client.Query().Search(ctx, r)
+		s.Add(resp, time.Since(qstart), err)
+		if err != nil {
+			log.Printf("Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
+			if *count == 1 {
+				return
+			}
+			continue
+		}
+
+		if tw != nil {
+			if err := tw.WriteResults(resp.Results); err != nil {
+				log.Printf("error writing tokens: %v", err)
+				return
+			}
+		}
+
+		if !*quiet {
+			if err := rw.WriteResults(resp.Results); err != nil {
+				log.Println(err)
+				return
+			}
+
+			if len(resp.Aggregates) > 0 {
+				b, err := json.MarshalIndent(resp.Aggregates, "", "  ")
+				if err != nil {
+					log.Printf("could not write out aggregates (%v): %v", resp.Aggregates, err)
+				}
+				fmt.Fprintln(info)
+				fmt.Fprintln(info, "Aggregates:")
+				fmt.Fprintln(info, string(b))
+			}
+		}
+	}
+
+	if err := rw.Flush(); err != nil {
+		log.Printf("error writing results: %v", err)
+	}
+	s.Print(info, time.Since(start))
+}
+
+// requestFromFlags builds a request from the command line flags.
+func requestFromFlags() (*sajari.Request, error) {
 	r := &sajari.Request{
 		Limit:  *limit,
 		Offset: *offset,
@@ -75,42 +237,57 @@ func main() {
 		for _, boost := range boosts {
 			boostSplit := strings.Split(boost, ":")
 			if len(boostSplit) != 2 {
-				log.Printf("index boost: expected two items field:value, got: %v", boost)
-				return
+				return nil, fmt.Errorf("index boost: expected two items field:value, got: %v", boost)
 			}
 			value, err := strconv.ParseFloat(boostSplit[1], 64)
 			if err != nil {
-				log.Printf("index boost: error parsing boost value %q: %v", boostSplit[1], err)
-				return
+				return nil, fmt.Errorf("index boost: error parsing boost value %q: %v", boostSplit[1], err)
 			}
 
 			iq.InstanceBoosts = append(iq.InstanceBoosts, sajari.FieldInstanceBoost(boostSplit[0], value))
 		}
 	}
 
-	if *sort != "" {
-		sortList := strings.Split(*sort, ",")
-		sorts := make([]sajari.Sort, 0, len(sortList))
-		for _, sortItem := range sortList {
-			sorts = append(sorts, sajari.SortByField(sortItem))
+	for _, fb := range []struct {
+		kind, value string
+	}{
+		{"filter", *filterBoost},
+		{"interval", *intervalBoost},
+		{"element", *elementBoost},
+		{"text", *textBoost},
+	} {
+		if fb.value == "" {
+			continue
+		}
+		bs, err := parseFieldBoosts(fb.kind, fb.value)
+		if err != nil {
+			return nil, err
+		}
+		iq.FieldBoosts = append(iq.FieldBoosts, bs...)
+	}
+
+	if *featureBoost != "" {
+		bs, err := parseFeatureBoosts(*featureBoost)
+		if err != nil {
+			return nil, err
 		}
-		if len(sorts) > 0 {
-			r.Sort = sorts
+		r.FeatureQuery.FieldBoosts = bs
+	}
+
+	if *sortBy != "" {
+		sorts, err := parseSorts(*sortBy)
+		if err != nil {
+			return nil, err
 		}
+		r.Sort = sorts
 	}
 
 	if *filter != "" {
-		filterList := strings.Split(*filter, ",")
-		fs := make([]sajari.Filter, 0, len(filterList))
-		for _, filterItem := range filterList {
-			items := strings.SplitN(filterItem, ":", 2)
-			if len(items) != 2 {
-				log.Printf("filter: expected two items field[ ]op:value, got: %q", filterItem)
-				return
-			}
-			fs = append(fs, sajari.FieldFilter(items[0], items[1]))
+		f, err := parseFilter(*filter)
+		if err != nil {
+			return nil, err
 		}
-		r.Filter = sajari.AllFilters(fs...)
+		r.Filter = f
 	}
 
 	if *transforms != "" {
@@ -121,51 +298,34 @@ func main() {
 	}
 
 	if *aggregates != "" {
-		aggregateList := strings.Split(*aggregates, ",")
-		for _, aggregate := range aggregateList {
-			items := strings.SplitN(aggregate, ":", 3)
-			if len(items) != 3 {
-				log.Printf("aggregates: invalid aggregate %q (should be of the form type:field:name)", aggregate)
-				return
-			}
-			var a sajari.Aggregate
-			switch items[0] {
-			case "count":
-				a = sajari.CountAggregate(items[1])
-
-			default:
-				log.Printf("aggregates: invalid aggregate type %q", items[0])
-				return
-			}
-
-			if r.Aggregates == nil {
-				r.Aggregates = make(map[string]sajari.Aggregate)
-			}
-			r.Aggregates[items[2]] = a
+		ags, err := parseAggregates(*aggregates)
+		if err != nil {
+			return nil, err
 		}
+		r.Aggregates = ags
 	}
 
 	tr := sajari.Tracking{}
 
 	if *tracking != "" {
 		if *trackingField == "" {
-			log.Printf("must specify -tracking-field with -tracking")
-			return
+			return nil, fmt.Errorf("must specify -tracking-field with -tracking")
 		}
 
+		var err error
 		switch *tracking {
 		case "CLICK":
-			tr.Type = sajari.TrackingClick
+			tr, err = sajari.ClickTracking(*trackingField, nil)
 
 		case "POS_NEG":
-			tr.Type = sajari.TrackingPosNeg
+			tr, err = sajari.PosNegTracking(*trackingField, nil)
 
 		default:
-			log.Printf("unknown tracking type: %q", *tracking)
-			return
+			return nil, fmt.Errorf("unknown tracking type: %q", *tracking)
+		}
+		if err != nil {
+			return nil, err
 		}
-
-		tr.Field = *trackingField
 	}
 
 	if *trackingData != "" {
@@ -174,8 +334,7 @@ func main() {
 		for _, pair := range pairs {
 			kv := strings.Split(pair, ":")
 			if len(kv) != 2 {
-				log.Printf("expected 'key:value': got %q", kv)
-				return
+				return nil, fmt.Errorf("expected 'key:value': got %q", kv)
 			}
 			m[kv[0]] = kv[1]
 		}
@@ -184,58 +343,65 @@ func main() {
 
 	r.Tracking = tr
 	r.IndexQuery = iq
+	return r, nil
+}
 
-	client, err := sajari.New(*project, *collection, opts...)
-	if err != nil {
-		log.Printf("error from sajari.New(): %v", err)
-		return
-	}
-	defer func() {
-		if err := client.Close(); err != nil {
-			log.Printf("error closing Client: %v", err)
-		}
-	}()
-
-	totalResults := 0
-	totalTime := time.Duration(0)
-	totalReads := 0
-
-	for i := 0; i < *count; i++ {
-		ctx := context.Background()
-		resp, err := client.Query().Search(ctx, r)
-		if err != nil {
-			log.Printf("Code: %v Message: %v", grpc.Code(err), grpc.ErrorDesc(err))
-			return
-		}
+// scoreSorts are the sort keys which refer to ranking scores rather than
+// fields.
+var scoreSorts = map[string]bool{
+	"_score":         true,
+	"_index_score":   true,
+	"_feature_score": true,
+}
 
-		for _, result := range resp.Results {
-			b, err := json.MarshalIndent(result, "", "  ")
-			if err != nil {
-				log.Printf("could not write out result (%v): %v", result, err)
-			}
-			fmt.Println(string(b))
+// parseSorts parses a comma separated list of [-]field, where a leading -
+// reverses the sort order.  Sort keys are applied in order, so later keys
+// break ties in earlier ones.
+func parseSorts(s string) ([]sajari.Sort, error) {
+	sortList := strings.Split(s, ",")
+	sorts := make([]sajari.Sort, 0, len(sortList))
+	seen := make(map[string]bool, len(sortList))
+	for _, sortItem := range sortList {
+		sortItem = strings.TrimSpace(sortItem)
+		field := strings.TrimPrefix(sortItem, "-")
+		switch {
+		case field == "":
+			return nil, fmt.Errorf("invalid sort %q: empty sort key", s)
+
+		case strings.HasPrefix(field, "-"):
+			return nil, fmt.Errorf("invalid sort key %q: use a single - to reverse the sort order", sortItem)
+
+		case scoreSorts[field]:
+			return nil, fmt.Errorf("invalid sort key %q: sorting by score is not supported yet (results are ordered by score when no sort is set)", sortItem)
+
+		case seen[field]:
+			return nil, fmt.Errorf("invalid sort key %q: field %q already used in sort", sortItem, field)
 		}
+		seen[field] = true
+		sorts = append(sorts, sajari.SortByField(sortItem))
+	}
+	return sorts, nil
+}
 
-		if len(resp.Aggregates) > 0 {
-			b, err := json.MarshalIndent(resp.Aggregates, "", "  ")
-			if err != nil {
-				log.Printf("could not write out aggregates (%v): %v", resp.Aggregates, err)
-			}
-			fmt.Println()
-			fmt.Println("Aggregates:")
-			fmt.Println(string(b))
+// legacyFilterItem matches items in the comma separated field[ ]op:value
+// filter format.
+var legacyFilterItem = regexp.MustCompile(`^\s*[^\s:()"]+\s*(=|!=|>|>=|<|<=|~|!~|\^|\$):`)
+
+// parseFilter parses a filter expression (see sajari.ParseFilter).  For
+// compatibility, a comma separated list of field[ ]op:value is also accepted
+// and matches records satisfying all of them.
+func parseFilter(s string) (sajari.Filter, error) {
+	filterList := strings.Split(s, ",")
+	for _, filterItem := range filterList {
+		if !legacyFilterItem.MatchString(filterItem) {
+			return sajari.ParseFilter(s)
 		}
-
-		totalResults = resp.TotalResults
-		totalTime += resp.Time
-		totalReads += resp.Reads
 	}
 
-	fmt.Println("Total Results", totalResults)
-	fmt.Println("Reads", totalReads)
-	fmt.Println("Time", totalTime)
-
-	if totalReads > 0 {
-		fmt.Println("Time per Read:", time.Duration(int64(totalTime)/int64(totalReads)))
+	fs := make([]sajari.Filter, 0, len(filterList))
+	for _, filterItem := range filterList {
+		items := strings.SplitN(filterItem, ":", 2)
+		fs = append(fs, sajari.FieldFilter(items[0], items[1]))
 	}
+	return sajari.AllFilters(fs...), nil
 }
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// Column names used for result scores in csv and table output.  Field names
+// prefixed with _ are reserved, so these will not clash with record fields.
+const (
+	scoreColumn      = "_score"
+	indexScoreColumn = "_index_score"
+)
+
+// resultWriter writes query results in a particular format.
+type resultWriter interface {
+	// WriteResults writes the results from a single query.
+	WriteResults(rs []sajari.Result) error
+
+	// Flush writes any buffered data.
+	Flush() error
+}
+
+// newResultWriter returns a resultWriter for the named format.  Columns used
+// in csv and table output are taken from fields (using the alias of entries of
+// the form "field AS alias"), or from the first result if fields is empty.
+func newResultWriter(format string, w io.Writer, fields []string) (resultWriter, error) {
+	fields = columnNames(fields)
+
+	switch format {
+	case "json":
+		return &jsonWriter{w: w, indent: true}, nil
+
+	case "ndjson":
+		return &jsonWriter{w: w}, nil
+
+	case "csv":
+		cw := csv.NewWriter(w)
+		return &columnWriter{
+			fields: fields,
+			write:  cw.Write,
+			flush: func() error {
+				cw.Flush()
+				return cw.Error()
+			},
+		}, nil
+
+	case "table":
+		tw := tabwriter.NewWriter(w, 0, 4, 2, ' ', 0)
+		return &columnWriter{
+			fields: fields,
+			write: func(row []string) error {
+				_, err := fmt.Fprintln(tw, strings.Join(row, "\t"))
+				return err
+			},
+			flush: tw.Flush,
+		}, nil
+	}
+	return nil, fmt.Errorf("unknown output format %q (should be one of json, ndjson, csv or table)", format)
+}
+
+type jsonWriter struct {
+	w      io.Writer
+	indent bool
+}
+
+func (j *jsonWriter) WriteResults(rs []sajari.Result) error {
+	for _, r := range rs {
+		var b []byte
+		var err error
+		if j.indent {
+			b, err = json.MarshalIndent(r, "", "  ")
+		} else {
+			b, err = json.Marshal(r)
+		}
+		if err != nil {
+			return fmt.Errorf("could not write out result (%v): %v", r, err)
+		}
+		if _, err := fmt.Fprintln(j.w, string(b)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (j *jsonWriter) Flush() error { return nil }
+
+// columnWriter writes results as rows of field values, preceded by a header row.
+type columnWriter struct {
+	fields []string
+	header bool
+
+	write func([]string) error
+	flush func() error
+}
+
+func (c *columnWriter) WriteResults(rs []sajari.Result) error {
+	if len(rs) == 0 {
+		return nil
+	}
+
+	if !c.header {
+		if len(c.fields) == 0 {
+			for k := range rs[0].Values {
+				c.fields = append(c.fields, k)
+			}
+			sort.Strings(c.fields)
+		}
+
+		header := append([]string{scoreColumn, indexScoreColumn}, c.fields...)
+		if err := c.write(header); err != nil {
+			return err
+		}
+		c.header = true
+	}
+
+	for _, r := range rs {
+		row := make([]string, 0, len(c.fields)+2)
+		row = append(row, fmt.Sprintf("%v", r.Score), fmt.Sprintf("%v", r.IndexScore))
+		for _, f := range c.fields {
+			row = append(row, formatValue(r.Values[f]))
+		}
+		if err := c.write(row); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (c *columnWriter) Flush() error {
+	return c.flush()
+}
+
+func formatValue(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return ""
+
+	case []string:
+		return strings.Join(v, ",")
+	}
+	return fmt.Sprintf("%v", v)
+}
+
+// columnNames returns the keys of result values for the requested fields.
+func columnNames(fields []string) []string {
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		parts := strings.Fields(f)
+		if len(parts) == 3 && strings.EqualFold(parts[1], "AS") {
+			out = append(out, parts[2])
+			continue
+		}
+		out = append(out, strings.TrimSpace(f))
+	}
+	return out
+}
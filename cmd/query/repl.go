@@ -0,0 +1,172 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+const replHelp = `Enter text to search for it, or one of the following commands:
+
+  :limit N          set the number of results to return
+  :offset N         set the offset of the first result
+  :fields a,b,...   set the fields to return (empty for all fields)
+  :filter f         set the filter expression, e.g. type = article OR tag ~ news (empty to clear)
+  :sort s           set the sort, a comma separated list of [-]field (empty to clear)
+  :request          print the current request as JSON
+  :run              run the current request again
+  :help             print this message
+  :quit             exit
+`
+
+// runREPL reads commands from stdin and runs queries using client, starting
+// from the request r.  Each query updates r, so that subsequent commands refine
+// the previous request.
+func runREPL(client *sajari.Client, r *sajari.Request, rw resultWriter) {
+	in := bufio.NewScanner(os.Stdin)
+	fmt.Fprint(os.Stderr, replHelp)
+	for {
+		fmt.Fprint(os.Stderr, "> ")
+		if !in.Scan() {
+			fmt.Fprintln(os.Stderr)
+			return
+		}
+
+		line := strings.TrimSpace(in.Text())
+		if line == "" {
+			continue
+		}
+
+		if !strings.HasPrefix(line, ":") {
+			r.IndexQuery.Text = line
+			runQuery(client, r, rw, os.Stderr)
+			continue
+		}
+
+		cmd, arg := line, ""
+		if i := strings.Index(line, " "); i > 0 {
+			cmd, arg = line[:i], strings.TrimSpace(line[i+1:])
+		}
+
+		if err := replCommand(client, r, &rw, cmd, arg); err != nil {
+			if err == io.EOF {
+				return
+			}
+			fmt.Fprintln(os.Stderr, err)
+		}
+	}
+}
+
+// replCommand runs the command cmd with argument arg, updating r.  The result writer
+// rw is replaced when the requested fields change, so that csv and table columns match.
+func replCommand(client *sajari.Client, r *sajari.Request, rw *resultWriter, cmd, arg string) error {
+	switch cmd {
+	case ":limit", ":offset":
+		n, err := strconv.Atoi(arg)
+		if err != nil {
+			return fmt.Errorf("%v: expected number, got %q", cmd, arg)
+		}
+		if cmd == ":limit" {
+			r.Limit = n
+		} else {
+			r.Offset = n
+		}
+
+	case ":fields":
+		var fields []string
+		if arg != "" {
+			fields = strings.Split(arg, ",")
+		}
+		w, err := newResultWriter(*output, os.Stdout, fields)
+		if err != nil {
+			return err
+		}
+		if err := (*rw).Flush(); err != nil {
+			return err
+		}
+		r.Fields = fields
+		*rw = w
+
+	case ":filter":
+		var f sajari.Filter
+		if arg != "" {
+			var err error
+			f, err = parseFilter(arg)
+			if err != nil {
+				return err
+			}
+		}
+		r.Filter = f
+
+	case ":sort":
+		var sorts []sajari.Sort
+		if arg != "" {
+			var err error
+			sorts, err = parseSorts(arg)
+			if err != nil {
+				return err
+			}
+		}
+		r.Sort = sorts
+
+	case ":request":
+		b, err := sajari.MarshalRequestJSON(*r)
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(b))
+
+	case ":run":
+		runQuery(client, r, *rw, os.Stderr)
+
+	case ":help":
+		fmt.Fprint(os.Stderr, replHelp)
+
+	case ":quit", ":q", ":exit":
+		return io.EOF
+
+	default:
+		return fmt.Errorf("unknown command %q, type :help for a list of commands", cmd)
+	}
+	return nil
+}
+
+// runQuery runs a single query, writing results with rw and timing information to info.
+func runQuery(client *sajari.Client, r *sajari.Request, rw resultWriter, info io.Writer) {
+	start := time.Now()
+	resp, err := client.Query().Search(context.Background(), r)
+	if err != nil {
+		fmt.Fprintf(info, "Code: %v Message: %v\n", grpc.Code(err), grpc.ErrorDesc(err))
+		return
+	}
+	elapsed := time.Since(start)
+
+	if err := rw.WriteResults(resp.Results); err != nil {
+		fmt.Fprintln(info, err)
+	}
+	if err := rw.Flush(); err != nil {
+		fmt.Fprintln(info, err)
+	}
+
+	if len(resp.Aggregates) > 0 {
+		b, err := json.MarshalIndent(resp.Aggregates, "", "  ")
+		if err != nil {
+			fmt.Fprintf(info, "could not write out aggregates (%v): %v\n", resp.Aggregates, err)
+		}
+		fmt.Fprintln(info, "Aggregates:")
+		fmt.Fprintln(info, string(b))
+	}
+
+	fmt.Fprintf(info, "%d of %d results, %d reads, engine time %v, total time %v\n", len(resp.Results), resp.TotalResults, resp.Reads, resp.Time, elapsed)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"sort"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// tokenWriter writes the tracking tokens from results to a file, one per line
+// in the form key<TAB>type<TAB>token, where key is the value of the tracking
+// field and type is click, pos or neg.
+type tokenWriter struct {
+	field string
+	f     *os.File
+	w     *bufio.Writer
+}
+
+func newTokenWriter(path, field string) (*tokenWriter, error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return nil, err
+	}
+	return &tokenWriter{
+		field: field,
+		f:     f,
+		w:     bufio.NewWriter(f),
+	}, nil
+}
+
+func (t *tokenWriter) WriteResults(rs []sajari.Result) error {
+	for _, r := range rs {
+		types := make([]string, 0, len(r.Tokens))
+		for k := range r.Tokens {
+			types = append(types, k)
+		}
+		sort.Strings(types)
+
+		for _, typ := range types {
+			if _, err := fmt.Fprintf(t.w, "%v\t%v\t%v\n", r.Values[t.field], typ, r.Tokens[typ]); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (t *tokenWriter) Close() error {
+	if err := t.w.Flush(); err != nil {
+		t.f.Close()
+		return err
+	}
+	return t.f.Close()
+}
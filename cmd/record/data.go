@@ -0,0 +1,93 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// keyList is a flag.Value which collects field:value pairs from repeated
+// flags.
+type keyList []string
+
+func (ks *keyList) String() string {
+	return strings.Join(*ks, ",")
+}
+
+func (ks *keyList) Set(v string) error {
+	if len(strings.SplitN(v, ":", 2)) != 2 {
+		return fmt.Errorf("expected field:value, got %q", v)
+	}
+	*ks = append(*ks, v)
+	return nil
+}
+
+// keys returns the keys identified by the field:value pairs.
+func (ks keyList) keys() []*sajari.Key {
+	out := make([]*sajari.Key, 0, len(ks))
+	for _, k := range ks {
+		fieldValue := strings.SplitN(k, ":", 2)
+		out = append(out, sajari.NewKey(fieldValue[0], fieldValue[1]))
+	}
+	return out
+}
+
+// dataReader returns a reader for the -data flag.  Data is read from stdin if
+// the flag is "-" or "@-", from a file if it is "@path", and otherwise is the
+// flag value itself.
+func dataReader(data string) (io.ReadCloser, error) {
+	switch {
+	case data == "":
+		return nil, fmt.Errorf("no data found, supply json with -data")
+
+	case data == "-" || data == "@-":
+		return ioutil.NopCloser(os.Stdin), nil
+
+	case strings.HasPrefix(data, "@"):
+		return os.Open(data[1:])
+	}
+	return ioutil.NopCloser(strings.NewReader(data)), nil
+}
+
+// readRecords reads a sequence of JSON objects (a single object or newline
+// delimited JSON) from the -data flag.
+func readRecords(data string) ([]sajari.Record, error) {
+	r, err := dataReader(data)
+	if err != nil {
+		return nil, err
+	}
+	defer r.Close()
+
+	var out []sajari.Record
+	dec := json.NewDecoder(r)
+	for {
+		d := map[string]interface{}{}
+		if err := dec.Decode(&d); err != nil {
+			if err == io.EOF {
+				break
+			}
+			return nil, fmt.Errorf("error unmarshalling json from -data (record %d): %v", len(out)+1, err)
+		}
+
+		for k, v := range d {
+			if vv, ok := v.([]interface{}); ok {
+				x := make([]string, 0, len(vv))
+				for _, vvv := range vv {
+					x = append(x, fmt.Sprintf("%v", vvv))
+				}
+				d[k] = x
+			}
+		}
+		out = append(out, sajari.Record(d))
+	}
+
+	if len(out) == 0 {
+		return nil, fmt.Errorf("no records found in -data")
+	}
+	return out, nil
+}
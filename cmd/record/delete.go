@@ -0,0 +1,76 @@
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// matchingKeys returns keys for all records which match the filter f.  Keys
+// are built from the values of the field keyField, which must be unique.
+func matchingKeys(ctx context.Context, client *sajari.Client, f sajari.Filter, keyField string, pageSize int) ([]*sajari.Key, error) {
+	r := &sajari.Request{
+		Filter: f,
+		Limit:  pageSize,
+		Fields: []string{keyField},
+	}
+
+	var ks []*sajari.Key
+	for {
+		resp, err := client.Query().Search(ctx, r)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, res := range resp.Results {
+			v, ok := res.Values[keyField]
+			if !ok {
+				return nil, fmt.Errorf("result has no value for key field %q", keyField)
+			}
+			ks = append(ks, sajari.NewKey(keyField, v))
+		}
+
+		r.Offset += len(resp.Results)
+		if len(resp.Results) == 0 || r.Offset >= resp.TotalResults {
+			return ks, nil
+		}
+	}
+}
+
+// deleteByFilter deletes all records which match the filter f in batches of
+// batchSize, which must be positive (see -batch-size).  If dryRun is set then
+// the number of matching records is printed and nothing is deleted.
+func deleteByFilter(ctx context.Context, client *sajari.Client, f sajari.Filter, keyField string, batchSize int, dryRun bool) error {
+	if batchSize < 1 {
+		return fmt.Errorf("invalid batch size %d", batchSize)
+	}
+
+	ks, err := matchingKeys(ctx, client, f, keyField, batchSize)
+	if err != nil {
+		return fmt.Errorf("error finding records: %v", errMsg(err))
+	}
+
+	if dryRun {
+		fmt.Printf("%d records match filter\n", len(ks))
+		return nil
+	}
+
+	deleted := 0
+	for len(ks) > 0 {
+		n := batchSize
+		if n > len(ks) {
+			n = len(ks)
+		}
+
+		if err := client.DeleteMulti(ctx, ks[:n]); err != nil {
+			return fmt.Errorf("error deleting records (%d deleted so far): %v", deleted, errMsg(err))
+		}
+		deleted += n
+		ks = ks[n:]
+		log.Printf("Deleted %d records", deleted)
+	}
+	return nil
+}
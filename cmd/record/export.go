@@ -0,0 +1,45 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// export writes the values of all records matching the filter f (or all records
+// if f is nil) to w as newline delimited JSON.  Records are read by paging
+// through search results, and only the given fields are written (all fields
+// if empty).  Returns the number of records written.
+func export(ctx context.Context, client *sajari.Client, w io.Writer, f sajari.Filter, fields []string, pageSize int) (int, error) {
+	r := &sajari.Request{
+		Filter: f,
+		Limit:  pageSize,
+		Fields: fields,
+	}
+
+	bw := bufio.NewWriter(w)
+	enc := json.NewEncoder(bw)
+	n := 0
+	for {
+		resp, err := client.Query().Search(ctx, r)
+		if err != nil {
+			return n, err
+		}
+
+		for _, res := range resp.Results {
+			if err := enc.Encode(res.Values); err != nil {
+				return n, err
+			}
+			n++
+		}
+
+		r.Offset += len(resp.Results)
+		if len(resp.Results) == 0 || r.Offset >= resp.TotalResults {
+			return n, bw.Flush()
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"strings"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// parseFilter parses a filter expression (see sajari.ParseFilter).  For
+// compatibility, a comma separated list of expressions, e.g.
+// "expires < 1500000000,type = article", is also accepted and matches records
+// satisfying all of them.
+func parseFilter(s string) (sajari.Filter, error) {
+	f, err := sajari.ParseFilter(s)
+	if err == nil || !strings.Contains(s, ",") {
+		return f, err
+	}
+
+	var fs []sajari.Filter
+	for _, expr := range strings.Split(s, ",") {
+		f, err := sajari.ParseFilter(expr)
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+	return sajari.AllFilters(fs...), nil
+}
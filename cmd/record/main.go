@@ -1,10 +1,10 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
+	"os"
 	"strings"
 
 	"golang.org/x/net/context"
@@ -12,41 +12,44 @@ import (
 	"google.golang.org/grpc"
 
 	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
 )
 
 var (
-	endpoint   = flag.String("endpoint", "", "engine endpoint `address`, uses default if not set")
-	project    = flag.String("project", "", "project `name` to query")
-	collection = flag.String("collection", "", "collection `name` to query")
-	creds      = flag.String("creds", "", "calling credentials in the form `key-id,key-secret`")
+	conn = cliutil.AddFlags(flag.CommandLine)
 
-	add       = flag.Bool("add", false, "add a record")
-	mutate    = flag.String("mutate", "", "`field:value` pair to identify a record")
-	get       = flag.String("get", "", "`field:value` pair to identify a record")
-	deleteKey = flag.String("delete", "", "`field:value` pair which identifies the record to delete")
+	add    = flag.Bool("add", false, "add a record, or many records if -data is newline delimited JSON")
+	mutate = flag.String("mutate", "", "`field:value` pair to identify a record")
 
-	data = flag.String("data", "", "`json` map of keys to values")
+	output = flag.String("o", "text", "output `format` for -get and -exists, one of text or json")
+
+	data = flag.String("data", "", "`json` map of keys to values, @file to read from a file, or - to read from stdin")
+
+	deleteFilter = flag.String("delete-filter", "", "delete all records matching the `filter`, e.g. \"expires < 1500000000 AND type = article\"")
+	dryRun       = flag.Bool("dry-run", false, "with -delete-filter, print the number of matching records without deleting them")
+	keyField     = flag.String("key-field", "_id", "unique `field` used to identify records found by -delete-filter")
+	batchSize    = flag.Int("batch-size", 100, "add, delete or export records in batches of at most `N`")
+
+	exportFile = flag.String("export", "", "export records as newline delimited JSON to `file` (- for stdout)")
+	filter     = flag.String("filter", "", "with -export, only export records matching the `filter`, e.g. \"type = article OR type = blog\"")
+	fields     = flag.String("fields", "", "with -export, comma separated `list` of fields to export (all fields if empty)")
 )
 
-func newClient() *sajari.Client {
-	var opts []sajari.Opt
-	if *endpoint != "" {
-		opts = append(opts, sajari.WithEndpoint(*endpoint))
-	}
+var getKeys, existsKeys, deleteKeys keyList
 
-	if *creds != "" {
-		credsSplit := strings.Split(*creds, ",")
-		if len(credsSplit) != 2 {
-			log.Printf("creds: expected 'id,secret', got '%v'", *creds)
-			return nil
-		}
-		kc := sajari.KeyCredentials(credsSplit[0], credsSplit[1])
-		opts = append(opts, sajari.WithCredentials(kc))
-	}
+func init() {
+	flag.Var(&getKeys, "get", "`field:value` pair to identify a record (can be repeated)")
+	flag.Var(&existsKeys, "exists", "`field:value` pair to check for existence (can be repeated), exits with status 0 if all records exist, 1 if any are missing and 2 on error")
+	flag.Var(&deleteKeys, "delete", "`field:value` pair which identifies the record to delete (can be repeated)")
+}
 
-	client, err := sajari.New(*project, *collection, opts...)
+// newClient creates a client, exiting with exitError if it fails (status 1 is
+// reserved for missing records, see -get and -exists).
+func newClient() *sajari.Client {
+	client, err := conn.NewClient()
 	if err != nil {
-		log.Fatal("error from sajari.New():", err)
+		log.Printf("error creating client: %v", err)
+		os.Exit(exitError)
 	}
 	return client
 }
@@ -58,62 +61,76 @@ func errMsg(err error) string {
 func main() {
 	flag.Parse()
 
-	if *get != "" {
-		fieldValue := strings.SplitN(*get, ":", 2)
-		if len(fieldValue) != 2 {
-			log.Fatalln("-get value must be of the form field:value")
-		}
+	if *output != "text" && *output != "json" {
+		log.Printf("unknown output format %q (should be text or json)", *output)
+		os.Exit(exitError)
+	}
 
-		k := sajari.NewKey(fieldValue[0], fieldValue[1])
-		d, err := newClient().Get(context.Background(), k)
-		if err != nil {
-			log.Fatalf("error from Get(%v): %v\n", k, err)
-		}
+	if *batchSize < 1 {
+		log.Printf("-batch-size must be at least 1, got %d", *batchSize)
+		os.Exit(exitError)
+	}
 
-		b, err := json.MarshalIndent(d, "", "  ")
-		if err != nil {
-			log.Fatalf("error marshaling JSON output: %v\n", err)
-		}
+	if len(getKeys) > 0 {
+		os.Exit(get(newClient(), getKeys, *output))
+	}
 
-		fmt.Println(string(b))
-		return
+	if len(existsKeys) > 0 {
+		os.Exit(exists(newClient(), existsKeys, *output))
 	}
 
 	if *add {
-		if *data == "" {
-			log.Fatalln("no data found, supply json string with -data")
-		}
-		d := map[string]interface{}{}
-		if err := json.Unmarshal([]byte(*data), &d); err != nil {
-			log.Fatalf("got error unmarshalling json from -data: %v\n", err)
+		rs, err := readRecords(*data)
+		if err != nil {
+			log.Fatalln(err)
 		}
 
-		for k, v := range d {
-			if vv, ok := v.([]interface{}); ok {
-				x := make([]string, 0, len(vv))
-				for _, vvv := range vv {
-					x = append(x, fmt.Sprintf("%v", vvv))
+		client := newClient()
+		failed := 0
+		for len(rs) > 0 {
+			n := *batchSize
+			if n > len(rs) {
+				n = len(rs)
+			}
+
+			ks, err := client.AddMulti(context.Background(), rs[:n])
+			if err != nil {
+				me, ok := err.(sajari.MultiError)
+				if !ok {
+					log.Fatalf("got error adding records: %v\n", errMsg(err))
+				}
+				for i, err := range me {
+					if err != nil {
+						log.Printf("got error adding record: %v\n", errMsg(err))
+						failed++
+						if i < len(ks) {
+							ks[i] = nil
+						}
+					}
 				}
-				d[k] = x
 			}
-		}
 
-		k, err := newClient().Add(context.Background(), d)
-		if err != nil {
-			log.Fatalf("got error adding record: %v\n", errMsg(err))
+			for _, k := range ks {
+				if k != nil {
+					fmt.Println(k)
+				}
+			}
+			rs = rs[n:]
 		}
 
-		fmt.Println(k)
+		if failed > 0 {
+			log.Fatalf("%d records could not be added\n", failed)
+		}
 		return
 	}
 
 	if *mutate != "" {
-		if *data == "" {
-			log.Fatalln("no data found, supply json string with -data")
+		rs, err := readRecords(*data)
+		if err != nil {
+			log.Fatalln(err)
 		}
-		d := map[string]interface{}{}
-		if err := json.Unmarshal([]byte(*data), &d); err != nil {
-			log.Fatalf("got error unmarshalling json from -data: %v\n", err)
+		if len(rs) != 1 {
+			log.Fatalln("-mutate expects a single json map in -data")
 		}
 
 		ids := strings.Split(*mutate, ":")
@@ -122,23 +139,72 @@ func main() {
 		}
 		ctx := context.Background()
 		k := sajari.NewKey(ids[0], ids[1])
-		if err := newClient().Mutate(ctx, k, sajari.SetFields(d)...); err != nil {
+		if err := newClient().Mutate(ctx, k, sajari.SetFields(rs[0])...); err != nil {
 			log.Fatalf("error mutating record: %v\n", errMsg(err))
 		}
 		return
 	}
 
-	if *deleteKey != "" {
-		fieldValue := strings.SplitN(*deleteKey, ":", 2)
-		if len(fieldValue) != 2 {
-			log.Fatalf("-delete value must be of the form field:value")
+	if len(deleteKeys) > 0 {
+		if err := newClient().DeleteMulti(context.Background(), deleteKeys.keys()); err != nil {
+			me, ok := err.(sajari.MultiError)
+			if !ok {
+				log.Fatalf("error from DeleteMulti(%v): %v\n", deleteKeys.String(), errMsg(err))
+			}
+			for i, err := range me {
+				if err != nil {
+					log.Printf("error from Delete(%v): %v\n", deleteKeys[i], errMsg(err))
+				}
+			}
+			os.Exit(1)
+		}
+		return
+	}
+
+	if *deleteFilter != "" {
+		f, err := parseFilter(*deleteFilter)
+		if err != nil {
+			log.Fatalf("-delete-filter: %v\n", err)
 		}
 
-		k := sajari.NewKey(fieldValue[0], fieldValue[1])
-		if err := newClient().Delete(context.Background(), k); err != nil {
-			log.Fatalf("error from Delete(%v): %v\n", k, errMsg(err))
+		if err := deleteByFilter(context.Background(), newClient(), f, *keyField, *batchSize, *dryRun); err != nil {
+			log.Fatalln(err)
+		}
+		return
+	}
+	if *exportFile != "" {
+		var f sajari.Filter
+		if *filter != "" {
+			var err error
+			f, err = parseFilter(*filter)
+			if err != nil {
+				log.Fatalf("-filter: %v\n", err)
+			}
+		}
+
+		var fs []string
+		if *fields != "" {
+			fs = strings.Split(*fields, ",")
+		}
+
+		w := os.Stdout
+		if *exportFile != "-" {
+			var err error
+			w, err = os.Create(*exportFile)
+			if err != nil {
+				log.Fatalf("error creating export file: %v\n", err)
+			}
+		}
+
+		n, err := export(context.Background(), newClient(), w, f, fs, *batchSize)
+		if err != nil {
+			log.Fatalf("error exporting records (%d written): %v\n", n, errMsg(err))
+		}
+		if err := w.Close(); err != nil {
+			log.Fatalf("error closing export file: %v\n", err)
 		}
+		log.Printf("Exported %d records", n)
 		return
 	}
-	log.Fatalln("command not found, please use -add, -mutate, or -get")
+	log.Fatalln("command not found, please use -add, -mutate, -get, -delete, -delete-filter or -export")
 }
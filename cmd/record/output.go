@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// Exit codes used by -get and -exists.
+const (
+	exitFound    = 0
+	exitNotFound = 1
+	exitError    = 2
+)
+
+// get fetches the records identified by ks and writes them to stdout in the
+// given format.  Returns the exit code: exitFound if all records were found,
+// exitNotFound if any were missing and exitError if any other error occurred.
+func get(client *sajari.Client, ks keyList, format string) int {
+	ds, err := client.GetMulti(context.Background(), ks.keys())
+	code := exitFound
+	if err != nil {
+		me, ok := err.(sajari.MultiError)
+		if !ok {
+			log.Printf("error from GetMulti(%v): %v\n", ks.String(), errMsg(err))
+			return exitError
+		}
+		code = multiErrorCode(ks, me, "Get")
+	}
+
+	for _, d := range ds {
+		if d == nil {
+			continue
+		}
+
+		var b []byte
+		var err error
+		if format == "json" {
+			b, err = json.Marshal(map[string]interface{}(d))
+		} else {
+			b, err = json.MarshalIndent(map[string]interface{}(d), "", "  ")
+		}
+		if err != nil {
+			log.Printf("error marshaling JSON output: %v\n", err)
+			return exitError
+		}
+		fmt.Println(string(b))
+	}
+	return code
+}
+
+// exists checks whether the records identified by ks exist and writes the
+// results to stdout in the given format.  Returns the exit code: exitFound if
+// all records exist, exitNotFound if any are missing and exitError on error.
+func exists(client *sajari.Client, ks keyList, format string) int {
+	found, err := client.ExistsMulti(context.Background(), ks.keys())
+	if err != nil {
+		if me, ok := err.(sajari.MultiError); ok {
+			multiErrorCode(ks, me, "Exists")
+			return exitError
+		}
+		log.Printf("error from ExistsMulti(%v): %v\n", ks.String(), errMsg(err))
+		return exitError
+	}
+
+	code := exitFound
+	for i, k := range ks {
+		if !found[i] {
+			code = exitNotFound
+		}
+
+		if format == "json" {
+			b, err := json.Marshal(struct {
+				Key    string `json:"key"`
+				Exists bool   `json:"exists"`
+			}{k, found[i]})
+			if err != nil {
+				log.Printf("error marshaling JSON output: %v\n", err)
+				return exitError
+			}
+			fmt.Println(string(b))
+			continue
+		}
+
+		status := "exists"
+		if !found[i] {
+			status = "not found"
+		}
+		fmt.Printf("%v %v\n", k, status)
+	}
+	return code
+}
+
+// multiErrorCode logs the errors in me from calling method with the keys ks,
+// and returns the corresponding exit code.
+func multiErrorCode(ks keyList, me sajari.MultiError, method string) int {
+	code := exitFound
+	for i, err := range me {
+		switch {
+		case err == nil:
+
+		case err == sajari.ErrNoSuchRecord:
+			if code == exitFound {
+				code = exitNotFound
+			}
+
+		default:
+			log.Printf("error from %v(%v): %v\n", method, ks[i], errMsg(err))
+			code = exitError
+		}
+	}
+	return code
+}
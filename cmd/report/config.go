@@ -0,0 +1,146 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// config is the report definition read from the -config file, e.g.
+//
+//	{
+//	  "reports": [
+//	    {
+//	      "name": "articles",
+//	      "filter": "type = article",
+//	      "aggregates": [
+//	        {"name": "authors", "type": "count", "field": "author"},
+//	        {"name": "avg-words", "type": "avg", "field": "words"},
+//	        {"name": "length", "type": "bucket", "other": true, "buckets": [
+//	          {"name": "short", "filter": "words < 500"},
+//	          {"name": "long", "filter": "words >= 500"}
+//	        ]}
+//	      ]
+//	    }
+//	  ]
+//	}
+type config struct {
+	Reports []report `json:"reports"`
+}
+
+// report is a single aggregate-only query.
+type report struct {
+	Name       string      `json:"name"`
+	Text       string      `json:"text"`
+	Filter     string      `json:"filter"`
+	Aggregates []aggregate `json:"aggregates"`
+}
+
+// aggregate defines an aggregate to run in a report.  Type is one of count, min, max,
+// avg, sum or bucket.  With Other set, bucket aggregates also count the records which
+// don't match any bucket, written with key _other.
+type aggregate struct {
+	Name    string   `json:"name"`
+	Type    string   `json:"type"`
+	Field   string   `json:"field"`
+	Buckets []bucket `json:"buckets"`
+	Other   bool     `json:"other"`
+}
+
+// otherBucket is the name of the bucket counting records which don't match any other
+// bucket (see aggregate.Other).
+const otherBucket = "_other"
+
+type bucket struct {
+	Name   string `json:"name"`
+	Filter string `json:"filter"`
+}
+
+func readConfig(path string) (*config, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	c := &config{}
+	if err := json.NewDecoder(f).Decode(c); err != nil {
+		return nil, fmt.Errorf("error reading config %v: %v", path, err)
+	}
+	return c, nil
+}
+
+// request returns the request for the report.
+func (r report) request() (*sajari.Request, error) {
+	req := &sajari.Request{
+		IndexQuery: sajari.IndexQuery{
+			Text: r.Text,
+		},
+		SkipResults: true,
+		Aggregates:  make(map[string]sajari.Aggregate, len(r.Aggregates)),
+	}
+
+	if r.Filter != "" {
+		f, err := sajari.ParseFilter(r.Filter)
+		if err != nil {
+			return nil, fmt.Errorf("report %q: filter: %v", r.Name, err)
+		}
+		req.Filter = f
+	}
+
+	for _, a := range r.Aggregates {
+		if _, ok := req.Aggregates[a.Name]; ok {
+			return nil, fmt.Errorf("report %q: duplicate aggregate %q", r.Name, a.Name)
+		}
+		agg, err := a.aggregate()
+		if err != nil {
+			return nil, fmt.Errorf("report %q: aggregate %q: %v", r.Name, a.Name, err)
+		}
+		req.Aggregates[a.Name] = agg
+	}
+	return req, nil
+}
+
+func (a aggregate) aggregate() (sajari.Aggregate, error) {
+	if a.Type != "bucket" && a.Field == "" {
+		return nil, fmt.Errorf("field must be set")
+	}
+
+	switch a.Type {
+	case "count":
+		return sajari.CountAggregate(a.Field), nil
+
+	case "min":
+		return sajari.MinAggregate(a.Field), nil
+
+	case "max":
+		return sajari.MaxAggregate(a.Field), nil
+
+	case "avg":
+		return sajari.AvgAggregate(a.Field), nil
+
+	case "sum":
+		return sajari.SumAggregate(a.Field), nil
+
+	case "bucket":
+		bs := make([]sajari.Bucket, 0, len(a.Buckets))
+		for _, b := range a.Buckets {
+			f, err := sajari.ParseFilter(b.Filter)
+			if err != nil {
+				return nil, fmt.Errorf("bucket %q: %v", b.Name, err)
+			}
+			bs = append(bs, sajari.Bucket{
+				Name:   b.Name,
+				Filter: f,
+			})
+		}
+		other := ""
+		if a.Other {
+			other = otherBucket
+		}
+		return sajari.OrderedBuckets(other, bs...), nil
+	}
+	return nil, fmt.Errorf("unknown type %q (should be one of count, min, max, avg, sum or bucket)", a.Type)
+}
@@ -0,0 +1,167 @@
+package main
+
+import (
+	"encoding/csv"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strconv"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
+)
+
+var (
+	conn = cliutil.AddFlags(flag.CommandLine)
+
+	configFile = flag.String("config", "", "`path` to the JSON report definitions")
+	out        = flag.String("o", "-", "`path` to write CSV output to (- for stdout)")
+	timeout    = flag.Duration("timeout", time.Minute, "`duration` to wait for all reports to complete")
+	header     = flag.Bool("header", true, "write a header row")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %v [flags] -config reports.json\n\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Runs the aggregate-only queries defined in the config file and writes the")
+	fmt.Fprintln(os.Stderr, "aggregate values as CSV rows of report,aggregate,key,value.  Each report")
+	fmt.Fprintln(os.Stderr, "also writes its total number of results with aggregate _total.")
+	fmt.Fprintln(os.Stderr)
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	if err := run(); err != nil {
+		log.Println(err)
+		os.Exit(1)
+	}
+}
+
+// run runs the reports.  Rows written before an error occurs are still flushed to
+// the output.
+func run() error {
+	if *configFile == "" {
+		return fmt.Errorf("must specify -config")
+	}
+
+	cfg, err := readConfig(*configFile)
+	if err != nil {
+		return err
+	}
+
+	// Build all the requests before running any, so that config errors are
+	// reported without partial output.
+	reqs := make([]*sajari.Request, 0, len(cfg.Reports))
+	for _, r := range cfg.Reports {
+		req, err := r.request()
+		if err != nil {
+			return err
+		}
+		reqs = append(reqs, req)
+	}
+
+	client, err := conn.NewClient()
+	if err != nil {
+		return fmt.Errorf("error creating client: %v", err)
+	}
+	defer client.Close()
+
+	w := os.Stdout
+	if *out != "-" {
+		f, err := os.Create(*out)
+		if err != nil {
+			return err
+		}
+		defer f.Close()
+		w = f
+	}
+
+	cw := csv.NewWriter(w)
+	if *header {
+		cw.Write([]string{"report", "aggregate", "key", "value"})
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	err = writeReports(ctx, client, cfg, reqs, cw)
+	cw.Flush()
+	if err != nil {
+		return err
+	}
+	if err := cw.Error(); err != nil {
+		return fmt.Errorf("error writing output: %v", err)
+	}
+	return nil
+}
+
+// writeReports runs each report in cfg using the respective request in reqs, writing
+// rows to cw.
+func writeReports(ctx context.Context, client *sajari.Client, cfg *config, reqs []*sajari.Request, cw *csv.Writer) error {
+	for i, r := range cfg.Reports {
+		resp, err := client.Query().Search(ctx, reqs[i])
+		if err != nil {
+			return fmt.Errorf("report %q: %v", r.Name, err)
+		}
+
+		cw.Write([]string{r.Name, "_total", "", strconv.Itoa(resp.TotalResults)})
+		for _, a := range r.Aggregates {
+			rows, err := aggregateRows(reqs[i].Aggregates[a.Name], resp.Aggregates[a.Name])
+			if err != nil {
+				return fmt.Errorf("report %q: aggregate %q: %v", r.Name, a.Name, err)
+			}
+			for _, row := range rows {
+				cw.Write(append([]string{r.Name, a.Name}, row...))
+			}
+		}
+	}
+	return nil
+}
+
+// aggregateRows returns key,value rows for the response v of the aggregate a.
+func aggregateRows(a sajari.Aggregate, v interface{}) ([][]string, error) {
+	if ob, ok := a.(*sajari.OrderedBucketAggregate); ok {
+		br, err := ob.Buckets(v)
+		if err != nil {
+			return nil, err
+		}
+		rows := make([][]string, 0, len(br.Buckets)+2)
+		for _, b := range br.Buckets {
+			rows = append(rows, []string{b.Name, strconv.Itoa(b.Count)})
+		}
+		rows = append(rows, []string{"_matched", strconv.Itoa(br.Matched)})
+		if br.Other != nil {
+			rows = append(rows, []string{otherBucket, strconv.Itoa(br.Other.Count)})
+		}
+		return rows, nil
+	}
+
+	switch v := v.(type) {
+	case nil:
+		return nil, nil
+
+	case sajari.CountResponse:
+		keys := make([]string, 0, len(v))
+		for k := range v {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		rows := make([][]string, 0, len(keys))
+		for _, k := range keys {
+			rows = append(rows, []string{k, strconv.Itoa(v[k])})
+		}
+		return rows, nil
+
+	case float64:
+		return [][]string{{"", strconv.FormatFloat(v, 'g', -1, 64)}}, nil
+	}
+	return nil, fmt.Errorf("unexpected response type %T", v)
+}
@@ -0,0 +1,176 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"log"
+	"sort"
+	"strings"
+
+	"golang.org/x/net/context"
+
+	sajari "code.sajari.com/sajari-sdk-go"
+)
+
+// change is a difference between a schema file and the live collection schema.
+type change struct {
+	// op is one of "+" (add field), "-" (remove field) or "~" (modify field).
+	op    string
+	field sajari.Field
+
+	// diffs describes the property changes in a modification.
+	diffs []string
+
+	// muts are the mutations needed to apply a modification.
+	muts []sajari.Mutation
+
+	// destructive is true for changes which can lose data or reject existing
+	// records, which are only applied with -allow-destructive.  These are
+	// removals, type and repeated changes, and making a field required or
+	// unique.
+	destructive bool
+}
+
+func (c change) String() string {
+	switch c.op {
+	case "+":
+		return fmt.Sprintf("+ %v (%v)", c.field.Name, describeField(c.field))
+	case "-":
+		return fmt.Sprintf("- %v (%v)", c.field.Name, describeField(c.field))
+	}
+	return fmt.Sprintf("~ %v: %v", c.field.Name, strings.Join(c.diffs, ", "))
+}
+
+func describeField(f sajari.Field) string {
+	props := []string{string(f.Type)}
+	for _, p := range []struct {
+		name string
+		set  bool
+	}{
+		{"repeated", f.Repeated},
+		{"required", f.Required},
+		{"indexed", f.Indexed},
+		{"unique", f.Unique},
+	} {
+		if p.set {
+			props = append(props, p.name)
+		}
+	}
+	return strings.Join(props, ", ")
+}
+
+// diffSchema returns the changes required to make the live schema match want.
+func diffSchema(want, live []sajari.Field) []change {
+	liveByName := make(map[string]sajari.Field, len(live))
+	for _, f := range live {
+		liveByName[f.Name] = f
+	}
+
+	wantNames := make(map[string]bool, len(want))
+	var out []change
+	for _, f := range want {
+		wantNames[f.Name] = true
+		lf, ok := liveByName[f.Name]
+		if !ok {
+			out = append(out, change{op: "+", field: f})
+			continue
+		}
+
+		c := change{op: "~", field: f}
+		if f.Type != lf.Type {
+			c.diffs = append(c.diffs, fmt.Sprintf("type %v -> %v", lf.Type, f.Type))
+			c.muts = append(c.muts, sajari.TypeMutation(f.Type))
+			c.destructive = true
+		}
+		if f.Repeated != lf.Repeated {
+			c.diffs = append(c.diffs, fmt.Sprintf("repeated %v -> %v", lf.Repeated, f.Repeated))
+			c.muts = append(c.muts, sajari.RepeatedMutation(f.Repeated))
+			c.destructive = true
+		}
+		if f.Required != lf.Required {
+			c.diffs = append(c.diffs, fmt.Sprintf("required %v -> %v", lf.Required, f.Required))
+			c.muts = append(c.muts, sajari.RequiredMutation(f.Required))
+			c.destructive = c.destructive || f.Required
+		}
+		if f.Indexed != lf.Indexed {
+			c.diffs = append(c.diffs, fmt.Sprintf("indexed %v -> %v", lf.Indexed, f.Indexed))
+			c.muts = append(c.muts, sajari.IndexedMutation(f.Indexed))
+		}
+		if f.Unique != lf.Unique {
+			c.diffs = append(c.diffs, fmt.Sprintf("unique %v -> %v", lf.Unique, f.Unique))
+			c.muts = append(c.muts, sajari.UniqueMutation(f.Unique))
+			c.destructive = c.destructive || f.Unique
+		}
+		if len(c.diffs) > 0 {
+			out = append(out, c)
+		}
+	}
+
+	var removed []sajari.Field
+	for _, f := range live {
+		if !wantNames[f.Name] {
+			removed = append(removed, f)
+		}
+	}
+	sort.Sort(fieldsByName(removed))
+	for _, f := range removed {
+		out = append(out, change{op: "-", field: f, destructive: true})
+	}
+	return out
+}
+
+type fieldsByName []sajari.Field
+
+func (fs fieldsByName) Len() int           { return len(fs) }
+func (fs fieldsByName) Less(i, j int) bool { return fs[i].Name < fs[j].Name }
+func (fs fieldsByName) Swap(i, j int)      { fs[i], fs[j] = fs[j], fs[i] }
+
+// printDiff writes the changes cs to w.
+func printDiff(w io.Writer, cs []change) {
+	if len(cs) == 0 {
+		fmt.Fprintln(w, "schema is up to date")
+		return
+	}
+	for _, c := range cs {
+		fmt.Fprintln(w, c)
+	}
+}
+
+// syncSchema applies the changes cs to the collection schema.  Fields are
+// added and additive modifications are applied, destructive modifications
+// only if allowDestructive is set.  Removing fields is not supported, so
+// removals are reported and skipped.
+func syncSchema(ctx context.Context, schema *sajari.Schema, cs []change, allowDestructive bool) error {
+	var add []sajari.Field
+	skipped := 0
+	for _, c := range cs {
+		switch {
+		case c.op == "+":
+			add = append(add, c.field)
+
+		case c.op == "-":
+			log.Printf("skipping removal of %q: removing fields is not supported", c.field.Name)
+
+		case c.destructive && !allowDestructive:
+			skipped++
+
+		default:
+			if err := schema.MutateField(ctx, c.field.Name, c.muts...); err != nil {
+				return fmt.Errorf("error mutating field %q: %v", c.field.Name, err)
+			}
+			log.Printf("Mutated field %q", c.field.Name)
+		}
+	}
+
+	if len(add) > 0 {
+		if err := schema.Add(ctx, add...); err != nil {
+			return fmt.Errorf("error adding fields: %v", err)
+		}
+		log.Printf("Added %d fields", len(add))
+	}
+
+	if skipped > 0 {
+		log.Printf("Skipped %d field modifications, use -allow-destructive to apply them", skipped)
+	}
+	return nil
+}
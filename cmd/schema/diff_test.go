@@ -0,0 +1,64 @@
+package main
+
+import (
+	"testing"
+
+	sajari "code.sajari.com/sajari-sdk-go"
+)
+
+func TestDiffSchema(t *testing.T) {
+	base := sajari.Field{Name: "title", Type: sajari.TypeString}
+
+	with := func(fn func(f *sajari.Field)) sajari.Field {
+		f := base
+		fn(&f)
+		return f
+	}
+
+	tests := []struct {
+		name        string
+		live, want  sajari.Field
+		destructive bool
+	}{
+		{"type", base, with(func(f *sajari.Field) { f.Type = sajari.TypeInteger }), true},
+		{"repeated", base, with(func(f *sajari.Field) { f.Repeated = true }), true},
+		{"not repeated", with(func(f *sajari.Field) { f.Repeated = true }), base, true},
+		{"required", base, with(func(f *sajari.Field) { f.Required = true }), true},
+		{"not required", with(func(f *sajari.Field) { f.Required = true }), base, false},
+		{"unique", base, with(func(f *sajari.Field) { f.Unique = true }), true},
+		{"not unique", with(func(f *sajari.Field) { f.Unique = true }), base, false},
+		{"indexed", base, with(func(f *sajari.Field) { f.Indexed = true }), false},
+		{"not indexed", with(func(f *sajari.Field) { f.Indexed = true }), base, false},
+		{"indexed and required", base, with(func(f *sajari.Field) { f.Indexed, f.Required = true, true }), true},
+	}
+
+	for _, tt := range tests {
+		cs := diffSchema([]sajari.Field{tt.want}, []sajari.Field{tt.live})
+		if len(cs) != 1 {
+			t.Errorf("%v: diffSchema returned %d changes, expected 1", tt.name, len(cs))
+			continue
+		}
+		c := cs[0]
+		if c.op != "~" {
+			t.Errorf("%v: op = %q, expected %q", tt.name, c.op, "~")
+		}
+		if c.destructive != tt.destructive {
+			t.Errorf("%v: destructive = %v, expected %v", tt.name, c.destructive, tt.destructive)
+		}
+		if len(c.muts) != len(c.diffs) || len(c.muts) == 0 {
+			t.Errorf("%v: %d mutations for %d diffs %v", tt.name, len(c.muts), len(c.diffs), c.diffs)
+		}
+	}
+
+	// Unchanged fields produce no changes, new fields are additive and removed
+	// fields are destructive.
+	other := sajari.Field{Name: "body", Type: sajari.TypeString}
+	cs := diffSchema([]sajari.Field{base, other}, []sajari.Field{base})
+	if len(cs) != 1 || cs[0].op != "+" || cs[0].field.Name != "body" || cs[0].destructive {
+		t.Errorf("diffSchema with new field = %v, expected only an additive + body", cs)
+	}
+	cs = diffSchema([]sajari.Field{base}, []sajari.Field{base, other})
+	if len(cs) != 1 || cs[0].op != "-" || cs[0].field.Name != "body" || !cs[0].destructive {
+		t.Errorf("diffSchema with removed field = %v, expected only a destructive - body", cs)
+	}
+}
@@ -13,48 +13,52 @@ import (
 	"golang.org/x/net/context"
 
 	sajari "code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
 )
 
 var (
-	endpoint   = flag.String("endpoint", "", "endpoint `address`, uses default if not set")
-	project    = flag.String("project", "", "project `name` to query")
-	collection = flag.String("collection", "", "collection `name` to query")
-	creds      = flag.String("creds", "", "calling credentials `key-id,key-secret`")
+	conn = cliutil.AddFlags(flag.CommandLine)
 
 	fetch        = flag.String("fetch", "-", "`path` to file to write JSON schema to, or '-' for stdout")
 	add          = flag.String("add", "", "`path` to file to read JSON schema from")
 	ignoreFields = flag.String("ignore-fields", "", "list of comma seperated fields `field1,field2,...` to ignore")
+
+	validate = flag.String("validate", "", "`path` to JSON schema file to check for errors, without contacting the API")
+
+	diff             = flag.String("diff", "", "`path` to JSON schema file to compare with the collection schema")
+	syncFields       = flag.Bool("sync", false, "with -diff, add fields and apply additive changes to the collection schema so that it matches the file")
+	allowDestructive = flag.Bool("allow-destructive", false, "with -sync, also apply type and repeated changes and make fields required or unique")
+
+	mutate = flag.String("mutate", "", "`name` of field to mutate using -set and -rename")
+	rename = flag.String("rename", "", "with -mutate, the new `name` for the field")
 )
 
+var setProps propertyList
+
+func init() {
+	flag.Var(&setProps, "set", "with -mutate, `property=value` to set on the field (can be repeated), where property is one of type, indexed, unique, repeated or required")
+}
+
 func main() {
 	flag.Parse()
 
-	var opts []sajari.Opt
-	if *endpoint != "" {
-		opts = append(opts, sajari.WithEndpoint(*endpoint))
-	}
-
-	if *creds != "" {
-		credsSplit := strings.Split(*creds, ",")
-		if len(credsSplit) != 2 {
-			log.Printf("creds: expected 'id,secret', got '%v'", *creds)
-			return
+	if *validate != "" {
+		errs, err := validateFile(*validate)
+		if err != nil {
+			log.Fatal(err)
 		}
-		kc := sajari.KeyCredentials(credsSplit[0], credsSplit[1])
-		opts = append(opts, sajari.WithCredentials(kc))
-	}
-
-	if *project == "" {
-		log.Fatal("project not set")
-	}
-
-	if *collection == "" {
-		log.Fatal("collection not set")
+		for _, err := range errs {
+			fmt.Fprintln(os.Stderr, err)
+		}
+		if len(errs) > 0 {
+			os.Exit(1)
+		}
+		return
 	}
 
-	client, err := sajari.New(*project, *collection, opts...)
+	client, err := conn.NewClient()
 	if err != nil {
-		log.Printf("error from sajari.New(): %v", err)
+		log.Printf("error creating client: %v", err)
 		return
 	}
 	defer func() {
@@ -79,6 +83,46 @@ func main() {
 		return
 	}
 
+	if *diff != "" {
+		ctx := context.Background()
+		live, err := schema.Fields(ctx)
+		if err != nil {
+			log.Fatalf("error fetching schema: %v", err)
+		}
+
+		var liveFields []sajari.Field
+		for _, f := range live {
+			if !ignoreFieldsMap[f.Name] {
+				liveFields = append(liveFields, f)
+			}
+		}
+
+		cs := diffSchema(getFields(*diff, ignoreFieldsMap), liveFields)
+		printDiff(os.Stdout, cs)
+
+		if *syncFields {
+			if err := syncSchema(ctx, schema, cs, *allowDestructive); err != nil {
+				log.Fatal(err)
+			}
+		}
+		return
+	}
+
+	if *mutate != "" {
+		muts, err := mutations(setProps, *rename)
+		if err != nil {
+			log.Fatalf("error parsing mutations: %v", err)
+		}
+		if len(muts) == 0 {
+			log.Fatal("no mutations given, use -set or -rename")
+		}
+
+		if err := schema.MutateField(context.Background(), *mutate, muts...); err != nil {
+			log.Fatalf("error mutating field: %v", err)
+		}
+		return
+	}
+
 	if *fetch != "" {
 		fields, err := schema.Fields(context.Background())
 		if err != nil {
@@ -0,0 +1,75 @@
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	sajari "code.sajari.com/sajari-sdk-go"
+)
+
+// propertyList is a flag.Value which collects property=value pairs from
+// repeated (or comma separated) flags.
+type propertyList []string
+
+func (p *propertyList) String() string {
+	return strings.Join(*p, ",")
+}
+
+func (p *propertyList) Set(v string) error {
+	for _, x := range strings.Split(v, ",") {
+		if len(strings.SplitN(x, "=", 2)) != 2 {
+			return fmt.Errorf("expected property=value, got %q", x)
+		}
+		*p = append(*p, x)
+	}
+	return nil
+}
+
+// mutations converts the property=value pairs in ps into field mutations, in the
+// order they were given, followed by a name mutation if rename is non-empty.
+func mutations(ps propertyList, rename string) ([]sajari.Mutation, error) {
+	var out []sajari.Mutation
+	for _, p := range ps {
+		kv := strings.SplitN(p, "=", 2)
+		prop, value := strings.ToLower(kv[0]), kv[1]
+
+		if prop == "type" {
+			t := sajari.Type(strings.ToUpper(value))
+			switch t {
+			case sajari.TypeString, sajari.TypeInteger, sajari.TypeFloat, sajari.TypeBoolean, sajari.TypeTimestamp:
+			default:
+				return nil, fmt.Errorf("invalid type %q", value)
+			}
+			out = append(out, sajari.TypeMutation(t))
+			continue
+		}
+
+		b, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid value for %v: expected true or false, got %q", prop, value)
+		}
+
+		switch prop {
+		case "indexed":
+			out = append(out, sajari.IndexedMutation(b))
+
+		case "unique":
+			out = append(out, sajari.UniqueMutation(b))
+
+		case "repeated":
+			out = append(out, sajari.RepeatedMutation(b))
+
+		case "required":
+			out = append(out, sajari.RequiredMutation(b))
+
+		default:
+			return nil, fmt.Errorf("unknown property %q (should be one of type, indexed, unique, repeated or required)", prop)
+		}
+	}
+
+	if rename != "" {
+		out = append(out, sajari.NameMutation(rename))
+	}
+	return out, nil
+}
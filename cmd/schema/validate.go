@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"strings"
+
+	sajari "code.sajari.com/sajari-sdk-go"
+)
+
+// validateFile checks the JSON schema file at path without contacting the API.
+// Returns an error for each problem found.
+func validateFile(path string) ([]error, error) {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading JSON schema file: %v", err)
+	}
+
+	s := Schema{}
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, fmt.Errorf("error unmarshalling JSON schema file: %v", err)
+	}
+	return validateFields(s.Fields), nil
+}
+
+// validateFields checks field names and properties.
+func validateFields(fs []Field) []error {
+	var errs []error
+	seen := make(map[string]bool, len(fs))
+	for i, f := range fs {
+		if f.Name == "" {
+			errs = append(errs, fmt.Errorf("field %d: name is empty", i+1))
+			continue
+		}
+
+		if seen[f.Name] {
+			errs = append(errs, fmt.Errorf("field %q: duplicate field name", f.Name))
+		}
+		seen[f.Name] = true
+
+		if strings.HasPrefix(f.Name, "_") {
+			errs = append(errs, fmt.Errorf("field %q: names beginning with _ are reserved", f.Name))
+		} else if !validName(f.Name) {
+			errs = append(errs, fmt.Errorf("field %q: names must start with a letter and contain only letters, digits and _", f.Name))
+		}
+
+		switch f.Type {
+		case sajari.TypeString, sajari.TypeInteger, sajari.TypeFloat, sajari.TypeBoolean, sajari.TypeTimestamp:
+			if f.Indexed && f.Type != sajari.TypeString {
+				errs = append(errs, fmt.Errorf("field %q: only %v fields can be indexed, not %v", f.Name, sajari.TypeString, f.Type))
+			}
+
+		default:
+			errs = append(errs, fmt.Errorf("field %q: invalid type %q (should be one of STRING, INTEGER, FLOAT, BOOLEAN or TIMESTAMP)", f.Name, f.Type))
+		}
+	}
+	return errs
+}
+
+func validName(name string) bool {
+	for i, r := range name {
+		switch {
+		case r >= 'a' && r <= 'z', r >= 'A' && r <= 'Z':
+		case i > 0 && (r >= '0' && r <= '9' || r == '_'):
+		default:
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,242 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"mime"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/extract"
+	"code.sajari.com/sajari-sdk-go/internal/cliutil"
+)
+
+var (
+	conn = cliutil.AddFlags(flag.CommandLine)
+
+	workers   = flag.Int("workers", 8, "fetch `N` pages concurrently")
+	batchSize = flag.Int("batch-size", 50, "submit records in groups of at most `N`")
+	timeout   = flag.Duration("timeout", 30*time.Second, "`timeout` for fetching each page")
+	userAgent = flag.String("user-agent", "sajari-sitemap-import", "`User-Agent` header sent when fetching pages")
+	maxPages  = flag.Int("max-pages", 0, "import at most `N` pages from the sitemap (0 for no limit)")
+
+	createSchema = flag.Bool("create-schema", false, "add the url, title, description, keywords and headings fields to the schema if they are missing")
+	debug        = flag.Bool("debug", false, "only print the extracted records, don't submit")
+)
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: %v [flags] sitemap-url\n", os.Args[0])
+	fmt.Fprintln(os.Stderr, "Sitemap indexes are followed, and sitemaps ending in .gz are decompressed.")
+	flag.PrintDefaults()
+}
+
+func main() {
+	flag.Usage = usage
+	flag.Parse()
+
+	u := flag.Arg(0)
+	if u == "" {
+		usage()
+		return
+	}
+
+	var client *sajari.Client
+	if !*debug {
+		var err error
+		client, err = conn.NewClient()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error creating client: %v\n", err)
+			return
+		}
+		defer client.Close()
+
+		if *createSchema {
+			if err := createSchemaFields(context.Background(), client); err != nil {
+				log.Printf("Error creating schema: %v", err)
+				return
+			}
+		}
+	}
+
+	hc := &http.Client{Timeout: *timeout}
+	urls, err := sitemapURLs(hc, u)
+	if err != nil {
+		log.Printf("Error reading sitemap: %v", err)
+		return
+	}
+	if *maxPages > 0 && len(urls) > *maxPages {
+		urls = urls[:*maxPages]
+	}
+	log.Printf("Found %d pages in sitemap", len(urls))
+
+	ch := make(chan string)
+	var mu sync.Mutex
+	var added, failed int
+
+	wg := sync.WaitGroup{}
+	for i := 0; i < *workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			var batch []sajari.Record
+			var batchURLs []string
+			send := func() {
+				n := sendBatch(client, batchURLs, batch)
+				mu.Lock()
+				added += n
+				failed += len(batch) - n
+				mu.Unlock()
+				batch, batchURLs = batch[:0], batchURLs[:0]
+			}
+
+			for pu := range ch {
+				rec, err := fetchRecord(hc, pu)
+				if err != nil {
+					log.Printf("Error fetching %v: %v", pu, err)
+					mu.Lock()
+					failed++
+					mu.Unlock()
+					continue
+				}
+
+				batch = append(batch, rec)
+				batchURLs = append(batchURLs, pu)
+				if len(batch) == *batchSize {
+					send()
+				}
+			}
+			if len(batch) > 0 {
+				send()
+			}
+		}()
+	}
+
+	for _, pu := range urls {
+		ch <- pu
+	}
+	close(ch)
+	wg.Wait()
+
+	log.Printf("Imported %d pages, %d failed", added, failed)
+}
+
+// fetchRecord fetches the page at u and extracts a record from it.
+func fetchRecord(hc *http.Client, u string) (sajari.Record, error) {
+	resp, err := get(hc, u)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if ct := resp.Header.Get("Content-Type"); ct != "" {
+		mt, _, err := mime.ParseMediaType(ct)
+		if err == nil && mt != "text/html" && mt != "application/xhtml+xml" {
+			return nil, fmt.Errorf("unsupported content type %q", mt)
+		}
+	}
+
+	d, err := extract.HTML(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	rec := d.Record()
+	rec[urlField] = u
+	return rec, nil
+}
+
+// sendBatch imports the records rs, which were fetched from urls, logging any
+// failures.  Pages which already exist in the collection (identified by their
+// url) are updated, and the rest are added.  With -debug the records are printed
+// instead.  Returns the number of records which were imported.
+func sendBatch(client *sajari.Client, urls []string, rs []sajari.Record) int {
+	if *debug {
+		for _, r := range rs {
+			b, err := json.MarshalIndent(map[string]interface{}(r), "", "  ")
+			if err != nil {
+				log.Fatal(err)
+			}
+			fmt.Println(string(b))
+		}
+		return len(rs)
+	}
+
+	ctx := context.Background()
+	ks := make([]*sajari.Key, len(urls))
+	for i, u := range urls {
+		ks[i] = sajari.NewKey(urlField, u)
+	}
+
+	exists, err := client.ExistsMulti(ctx, ks)
+	if err != nil {
+		logBatchErrors("checking for existing record", urls, err)
+		return 0
+	}
+
+	var addURLs, updateURLs []string
+	var toAdd []sajari.Record
+	var rms []sajari.RecordMutation
+	for i, r := range rs {
+		if !exists[i] {
+			addURLs = append(addURLs, urls[i])
+			toAdd = append(toAdd, r)
+			continue
+		}
+
+		values := make(map[string]interface{}, len(r))
+		for k, v := range r {
+			if k != urlField {
+				values[k] = v
+			}
+		}
+		updateURLs = append(updateURLs, urls[i])
+		rms = append(rms, sajari.RecordMutation{
+			Key:            ks[i],
+			FieldMutations: sajari.SetFields(values),
+		})
+	}
+
+	n := 0
+	if len(rms) > 0 {
+		err := client.MutateMulti(ctx, rms...)
+		n += len(rms) - logBatchErrors("updating", updateURLs, err)
+	}
+	if len(toAdd) > 0 {
+		_, err := client.AddMulti(ctx, toAdd)
+		n += len(toAdd) - logBatchErrors("adding", addURLs, err)
+	}
+	return n
+}
+
+// logBatchErrors logs the error err returned by an operation on the records
+// fetched from urls, which is described by op.  If err is a MultiError then
+// the error for each record is logged.  Returns the number of records which
+// failed.
+func logBatchErrors(op string, urls []string, err error) int {
+	if err == nil {
+		return 0
+	}
+
+	me, ok := err.(sajari.MultiError)
+	if !ok || len(me) != len(urls) {
+		log.Printf("Error %v %d records: %v", op, len(urls), err)
+		return len(urls)
+	}
+
+	n := 0
+	for i, err := range me {
+		if err != nil {
+			log.Printf("Error %v %v: %v", op, urls[i], err)
+			n++
+		}
+	}
+	return n
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"log"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/extract"
+)
+
+// urlField is the unique field set to the URL of each imported page.
+const urlField = "url"
+
+// schemaFields are the fields added to the collection schema by -create-schema.
+var schemaFields = append([]sajari.Field{
+	{
+		Name:        urlField,
+		Description: "URL of the page.",
+		Type:        sajari.TypeString,
+		Required:    true,
+		Unique:      true,
+	},
+}, extract.SchemaFields...)
+
+// createSchemaFields adds the fields in schemaFields which are missing from
+// the collection schema.
+func createSchemaFields(ctx context.Context, client *sajari.Client) error {
+	existing, err := client.Schema().Fields(ctx)
+	if err != nil {
+		return err
+	}
+
+	have := make(map[string]bool, len(existing))
+	for _, f := range existing {
+		have[f.Name] = true
+	}
+
+	var missing []sajari.Field
+	for _, f := range schemaFields {
+		if !have[f.Name] {
+			missing = append(missing, f)
+		}
+	}
+	if len(missing) == 0 {
+		return nil
+	}
+
+	if err := client.Schema().Add(ctx, missing...); err != nil {
+		return err
+	}
+	log.Printf("Added %d fields to schema", len(missing))
+	return nil
+}
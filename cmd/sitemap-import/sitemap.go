@@ -0,0 +1,115 @@
+package main
+
+import (
+	"compress/gzip"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// maxSitemapDepth limits how deeply sitemap indexes are followed.
+const maxSitemapDepth = 3
+
+// sitemap is a sitemap.xml document, which is either a list of page URLs
+// (<urlset>) or an index of other sitemaps (<sitemapindex>).
+type sitemap struct {
+	XMLName  xml.Name
+	URLs     []sitemapLoc `xml:"url"`
+	Sitemaps []sitemapLoc `xml:"sitemap"`
+}
+
+type sitemapLoc struct {
+	Loc string `xml:"loc"`
+}
+
+// sitemapURLs fetches the sitemap at u and returns the page URLs it lists,
+// following sitemap indexes.  Duplicate URLs are removed.
+func sitemapURLs(hc *http.Client, u string) ([]string, error) {
+	var out []string
+	seen := make(map[string]bool)
+	if err := readSitemap(hc, u, 0, seen, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func readSitemap(hc *http.Client, u string, depth int, seen map[string]bool, out *[]string) error {
+	if depth > maxSitemapDepth {
+		return fmt.Errorf("%v: sitemap indexes nested more than %d deep", u, maxSitemapDepth)
+	}
+
+	sm, err := fetchSitemap(hc, u)
+	if err != nil {
+		return err
+	}
+
+	for _, l := range sm.URLs {
+		loc := strings.TrimSpace(l.Loc)
+		if loc == "" || seen[loc] {
+			continue
+		}
+		seen[loc] = true
+		*out = append(*out, loc)
+	}
+
+	for _, l := range sm.Sitemaps {
+		loc := strings.TrimSpace(l.Loc)
+		if loc == "" {
+			continue
+		}
+		if err := readSitemap(hc, loc, depth+1, seen, out); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// fetchSitemap fetches and decodes the sitemap at u.  Sitemaps ending in .gz
+// are decompressed.
+func fetchSitemap(hc *http.Client, u string) (*sitemap, error) {
+	resp, err := get(hc, u)
+	if err != nil {
+		return nil, fmt.Errorf("%v: %v", u, err)
+	}
+	defer resp.Body.Close()
+
+	var r io.Reader = resp.Body
+	if strings.HasSuffix(resp.Request.URL.Path, ".gz") {
+		gr, err := gzip.NewReader(r)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", u, err)
+		}
+		defer gr.Close()
+		r = gr
+	}
+
+	var sm sitemap
+	if err := xml.NewDecoder(r).Decode(&sm); err != nil {
+		return nil, fmt.Errorf("%v: error decoding sitemap: %v", u, err)
+	}
+	if name := sm.XMLName.Local; name != "urlset" && name != "sitemapindex" {
+		return nil, fmt.Errorf("%v: expected <urlset> or <sitemapindex>, got <%v>", u, name)
+	}
+	return &sm, nil
+}
+
+// get fetches u, returning an error unless the response status is 200 OK.
+func get(hc *http.Client, u string) (*http.Response, error) {
+	req, err := http.NewRequest("GET", u, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("User-Agent", *userAgent)
+
+	resp, err := hc.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		resp.Body.Close()
+		return nil, fmt.Errorf("unexpected status %q", resp.Status)
+	}
+	return resp, nil
+}
@@ -0,0 +1,63 @@
+package sajari
+
+import (
+	"sync"
+
+	pb "code.sajari.com/protogen-go/sajari/api/query/v1"
+)
+
+// WithCoalescedSearches configures the client to coalesce concurrent identical searches
+// (with the same Request) into a single request, sharing its response between the
+// callers.  This protects the engine from bursts of the same popular query.
+//
+// The shared request is made using the context of the first caller, so if it is
+// cancelled then all of the coalesced searches fail.  Coalescing applies to
+// Query.Search and Query.SearchInto, and happens after the query cache (if enabled)
+// is checked.  Searches with tracking enabled are not coalesced, as each must receive
+// its own tracking tokens.
+func WithCoalescedSearches() Opt {
+	return func(c *Client) {
+		c.searchGroup = &searchGroup{}
+	}
+}
+
+// searchCall is an in-flight or completed search.
+type searchCall struct {
+	wg   sync.WaitGroup
+	resp *pb.SearchResponse
+	err  error
+}
+
+// searchGroup coalesces concurrent searches with the same key.
+type searchGroup struct {
+	mu sync.Mutex
+	m  map[string]*searchCall
+}
+
+// do calls fn and returns its result, unless a call with the same key is already
+// in flight, in which case it waits for that call and returns its result.
+func (g *searchGroup) do(key string, fn func() (*pb.SearchResponse, error)) (*pb.SearchResponse, error) {
+	g.mu.Lock()
+	if g.m == nil {
+		g.m = make(map[string]*searchCall)
+	}
+	if c, ok := g.m[key]; ok {
+		g.mu.Unlock()
+		c.wg.Wait()
+		return c.resp, c.err
+	}
+
+	c := &searchCall{}
+	c.wg.Add(1)
+	g.m[key] = c
+	g.mu.Unlock()
+
+	c.resp, c.err = fn()
+	c.wg.Done()
+
+	g.mu.Lock()
+	delete(g.m, key)
+	g.mu.Unlock()
+
+	return c.resp, c.err
+}
@@ -0,0 +1,100 @@
+package sajari
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+
+	pb "code.sajari.com/protogen-go/sajari/api/query/v1"
+	querypb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+)
+
+// stubSearch is an interceptor which answers searches without making a request, after
+// a short delay so that hedging and coalescing take effect.
+func stubSearch(calls *int64) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		resp, ok := reply.(*pb.SearchResponse)
+		if !ok {
+			return fmt.Errorf("unexpected method %v", method)
+		}
+		atomic.AddInt64(calls, 1)
+
+		select {
+		case <-time.After(2 * time.Millisecond):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+		resp.SearchResponse = &querypb.SearchResponse{
+			TotalResults: 1,
+			Time:         "1ms",
+		}
+		return nil
+	}
+}
+
+func TestConcurrentSearch(t *testing.T) {
+	var calls int64
+	c, err := New("project", "collection",
+		WithEndpoint("localhost:0"),
+		WithGRPCDialOption(grpc.WithInsecure()),
+		WithUnaryInterceptor(stubSearch(&calls)),
+		WithQueryCache(4, time.Minute),
+		WithCoalescedSearches(),
+		WithHedging(time.Millisecond, 2),
+	)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer c.Close()
+
+	c.RegisterProfile("boost", Profile{
+		FieldBoosts: []FieldBoost{FilterFieldBoost(FieldFilter("type =", "article"), 2)},
+	})
+
+	shared := &Request{
+		IndexQuery: IndexQuery{Text: "shared"},
+		Profile:    "boost",
+	}
+
+	var wg sync.WaitGroup
+	for i := 0; i < 16; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			cc := c
+			if i%2 == 1 {
+				cc = c.For("project", fmt.Sprintf("collection-%d", i%4))
+			}
+
+			for j := 0; j < 20; j++ {
+				c.RegisterProfile(fmt.Sprintf("p%d", i), Profile{})
+
+				r := shared
+				if j%3 == 0 {
+					r = &Request{IndexQuery: IndexQuery{Text: fmt.Sprintf("q%d", j%5)}}
+				}
+				resp, err := cc.Query().Search(context.Background(), r)
+				if err != nil {
+					t.Errorf("Search() error: %v", err)
+					return
+				}
+				if resp.TotalResults != 1 {
+					t.Errorf("Search() TotalResults = %d, want 1", resp.TotalResults)
+				}
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	if n := atomic.LoadInt64(&calls); n == 0 {
+		t.Errorf("no searches reached the interceptor")
+	}
+}
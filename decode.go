@@ -0,0 +1,216 @@
+package sajari
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+	"sync"
+	"time"
+
+	enginepb "code.sajari.com/protogen-go/sajari/engine"
+)
+
+// resultDecoder decodes result values directly into a caller-provided slice of
+// structs, avoiding the intermediate map[string]interface{} built for each Result.
+type resultDecoder struct {
+	slice reflect.Value // Addressable slice value.
+	ptr   bool          // Slice elements are pointers to structs.
+	elem  reflect.Type  // Struct type.
+	info  *structInfo
+}
+
+func newResultDecoder(dst interface{}) (*resultDecoder, error) {
+	v := reflect.ValueOf(dst)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Slice {
+		return nil, fmt.Errorf("sajari: decode destination must be a non-nil pointer to a slice, got %T", dst)
+	}
+
+	slice := v.Elem()
+	elem := slice.Type().Elem()
+	ptr := false
+	if elem.Kind() == reflect.Ptr {
+		elem = elem.Elem()
+		ptr = true
+	}
+	if elem.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("sajari: decode destination must be a slice of structs, got %T", dst)
+	}
+
+	return &resultDecoder{
+		slice: slice,
+		ptr:   ptr,
+		elem:  elem,
+		info:  structInfoFor(elem),
+	}, nil
+}
+
+// reset prepares the destination slice to receive n results.
+func (d *resultDecoder) reset(n int) {
+	if d.slice.Cap() < n {
+		d.slice.Set(reflect.MakeSlice(d.slice.Type(), 0, n))
+		return
+	}
+	d.slice.SetLen(0)
+}
+
+// decode appends a new element to the destination slice and sets its fields from values.
+func (d *resultDecoder) decode(values map[string]*enginepb.Value) error {
+	var sv reflect.Value
+	if d.ptr {
+		p := reflect.New(d.elem)
+		d.slice.Set(reflect.Append(d.slice, p))
+		sv = p.Elem()
+	} else {
+		d.slice.Set(reflect.Append(d.slice, reflect.Zero(d.elem)))
+		sv = d.slice.Index(d.slice.Len() - 1)
+	}
+
+	for k, v := range values {
+		i, ok := d.info.fields[k]
+		if !ok {
+			continue
+		}
+		if err := decodeValue(sv.Field(i), v); err != nil {
+			return fmt.Errorf("sajari: decoding field %q: %v", k, err)
+		}
+	}
+	return nil
+}
+
+// structInfo maps record field names to struct field indexes.
+type structInfo struct {
+	fields map[string]int
+}
+
+var structInfoCache = struct {
+	sync.Mutex
+	m map[reflect.Type]*structInfo
+}{
+	m: make(map[reflect.Type]*structInfo),
+}
+
+// structInfoFor returns the field mapping for the struct type t.  Struct fields are matched
+// to record fields using the `sajari:"name"` tag, or the struct field name if no tag is set.
+// Fields tagged with `sajari:"-"` and unexported fields are ignored.
+func structInfoFor(t reflect.Type) *structInfo {
+	structInfoCache.Lock()
+	defer structInfoCache.Unlock()
+
+	if si, ok := structInfoCache.m[t]; ok {
+		return si
+	}
+
+	si := &structInfo{
+		fields: make(map[string]int, t.NumField()),
+	}
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" {
+			continue
+		}
+		name := f.Name
+		if tag := f.Tag.Get("sajari"); tag != "" {
+			if tag == "-" {
+				continue
+			}
+			name = tag
+		}
+		si.fields[name] = i
+	}
+	structInfoCache.m[t] = si
+	return si
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func decodeValue(dst reflect.Value, v *enginepb.Value) error {
+	switch v := v.Value.(type) {
+	case *enginepb.Value_Single:
+		return decodeSingle(dst, v.Single)
+
+	case *enginepb.Value_Repeated_:
+		return decodeRepeated(dst, v.Repeated.Values)
+	}
+	return fmt.Errorf("unexpected type: %T", v.Value)
+}
+
+func decodeSingle(dst reflect.Value, s string) error {
+	if dst.Type() == timeType {
+		t, err := parseTimestamp(s)
+		if err != nil {
+			return err
+		}
+		dst.Set(reflect.ValueOf(t))
+		return nil
+	}
+
+	switch dst.Kind() {
+	case reflect.String:
+		dst.SetString(s)
+
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+		dst.SetBool(b)
+
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetInt(n)
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetUint(n)
+
+	case reflect.Float32, reflect.Float64:
+		n, err := strconv.ParseFloat(s, dst.Type().Bits())
+		if err != nil {
+			return err
+		}
+		dst.SetFloat(n)
+
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return fmt.Errorf("cannot decode into %v", dst.Type())
+		}
+		dst.Set(reflect.ValueOf(s))
+
+	case reflect.Slice:
+		return decodeRepeated(dst, []string{s})
+
+	default:
+		return fmt.Errorf("cannot decode into %v", dst.Type())
+	}
+	return nil
+}
+
+func decodeRepeated(dst reflect.Value, vs []string) error {
+	switch dst.Kind() {
+	case reflect.Slice:
+		out := reflect.MakeSlice(dst.Type(), len(vs), len(vs))
+		for i, s := range vs {
+			if err := decodeSingle(out.Index(i), s); err != nil {
+				return err
+			}
+		}
+		dst.Set(out)
+		return nil
+
+	case reflect.Interface:
+		if dst.NumMethod() != 0 {
+			return fmt.Errorf("cannot decode into %v", dst.Type())
+		}
+		cp := make([]string, len(vs))
+		copy(cp, vs)
+		dst.Set(reflect.ValueOf(cp))
+		return nil
+	}
+	return fmt.Errorf("cannot decode repeated value into %v", dst.Type())
+}
@@ -0,0 +1,402 @@
+package sajari
+
+import (
+	"bytes"
+	"fmt"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+
+	apipb "code.sajari.com/protogen-go/sajari/api/query/v1"
+	pb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+)
+
+// The functions in this file convert requests, filters, aggregates and sorts to and from
+// the JSON encoding of the query protocol (the form used by the JavaScript SDK and the
+// console), so that queries can be saved and shared.
+
+var jsonMarshaler = jsonpb.Marshaler{}
+
+func marshalJSON(m proto.Message) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := jsonMarshaler.Marshal(&buf, m); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// MarshalFilterJSON returns the JSON encoding of the filter f.
+func MarshalFilterJSON(f Filter) ([]byte, error) {
+	pf, err := f.proto()
+	if err != nil {
+		return nil, err
+	}
+	return marshalJSON(pf)
+}
+
+// UnmarshalFilterJSON parses the JSON encoding of a filter.
+func UnmarshalFilterJSON(b []byte) (Filter, error) {
+	pf := &pb.Filter{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(b), pf); err != nil {
+		return nil, err
+	}
+	return filterFromProto(pf)
+}
+
+// MarshalAggregateJSON returns the JSON encoding of the aggregate a.
+func MarshalAggregateJSON(a Aggregate) ([]byte, error) {
+	pa, err := a.proto()
+	if err != nil {
+		return nil, err
+	}
+	return marshalJSON(pa)
+}
+
+// UnmarshalAggregateJSON parses the JSON encoding of an aggregate.
+func UnmarshalAggregateJSON(b []byte) (Aggregate, error) {
+	pa := &pb.Aggregate{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(b), pa); err != nil {
+		return nil, err
+	}
+	return aggregateFromProto(pa)
+}
+
+// MarshalSortJSON returns the JSON encoding of the sort s.
+func MarshalSortJSON(s Sort) ([]byte, error) {
+	ps, err := s.proto()
+	if err != nil {
+		return nil, err
+	}
+	return marshalJSON(ps)
+}
+
+// UnmarshalSortJSON parses the JSON encoding of a sort.
+func UnmarshalSortJSON(b []byte) (Sort, error) {
+	ps := &pb.Sort{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(b), ps); err != nil {
+		return nil, err
+	}
+	return sortFromProto(ps)
+}
+
+var fieldFilterOps = map[pb.Filter_Field_Operator]string{
+	pb.Filter_Field_EQUAL_TO:                 "=",
+	pb.Filter_Field_NOT_EQUAL_TO:             "!=",
+	pb.Filter_Field_GREATER_THAN:             ">",
+	pb.Filter_Field_GREATER_THAN_OR_EQUAL_TO: ">=",
+	pb.Filter_Field_LESS_THAN:                "<",
+	pb.Filter_Field_LESS_THAN_OR_EQUAL_TO:    "<=",
+	pb.Filter_Field_CONTAINS:                 "~",
+	pb.Filter_Field_DOES_NOT_CONTAIN:         "!~",
+	pb.Filter_Field_HAS_PREFIX:               "^",
+	pb.Filter_Field_HAS_SUFFIX:               "$",
+}
+
+var combFilterOps = map[pb.Filter_Combinator_Operator]combFilterOp{
+	pb.Filter_Combinator_ALL:  combFilterOpAll,
+	pb.Filter_Combinator_ANY:  combFilterOpAny,
+	pb.Filter_Combinator_ONE:  combFilterOpOne,
+	pb.Filter_Combinator_NONE: combFilterOpNone,
+}
+
+func filterFromProto(pf *pb.Filter) (Filter, error) {
+	switch f := pf.Filter.(type) {
+	case *pb.Filter_Field_:
+		op, ok := fieldFilterOps[f.Field.Operator]
+		if !ok {
+			return nil, fmt.Errorf("invalid field filter operator: %v", f.Field.Operator)
+		}
+		if f.Field.Value == nil {
+			return nil, fmt.Errorf("field filter on %q has no value", f.Field.Field)
+		}
+		value, err := valueFromProto(f.Field.Value, false)
+		if err != nil {
+			return nil, err
+		}
+		return &fieldFilter{
+			field: f.Field.Field,
+			op:    op,
+			value: value,
+		}, nil
+
+	case *pb.Filter_Combinator_:
+		op, ok := combFilterOps[f.Combinator.Operator]
+		if !ok {
+			return nil, fmt.Errorf("invalid combinator operator: %v", f.Combinator.Operator)
+		}
+		filters := make([]Filter, 0, len(f.Combinator.Filters))
+		for _, x := range f.Combinator.Filters {
+			ff, err := filterFromProto(x)
+			if err != nil {
+				return nil, err
+			}
+			filters = append(filters, ff)
+		}
+		return newCombFilter(op, filters), nil
+
+	case *pb.Filter_Geo_:
+		var region GeoFilterRegion
+		switch f.Geo.Region {
+		case pb.Filter_Geo_INSIDE:
+			region = GeoFilterInside
+
+		case pb.Filter_Geo_OUTSIDE:
+			region = GeoFilterOutside
+
+		default:
+			return nil, fmt.Errorf("geo filter: invalid region '%v'", f.Geo.Region)
+		}
+		return GeoFilter(f.Geo.FieldLat, f.Geo.FieldLng, f.Geo.Lat, f.Geo.Lng, f.Geo.Radius, region), nil
+	}
+	return nil, fmt.Errorf("unexpected filter type: %T", pf.Filter)
+}
+
+func aggregateFromProto(pa *pb.Aggregate) (Aggregate, error) {
+	switch a := pa.Aggregate.(type) {
+	case *pb.Aggregate_Count_:
+		return CountAggregate(a.Count.Field), nil
+
+	case *pb.Aggregate_Bucket_:
+		bs := make([]Bucket, 0, len(a.Bucket.Buckets))
+		for _, b := range a.Bucket.Buckets {
+			if b.Filter == nil {
+				return nil, fmt.Errorf("bucket %q has no filter", b.Name)
+			}
+			f, err := filterFromProto(b.Filter)
+			if err != nil {
+				return nil, err
+			}
+			bs = append(bs, Bucket{
+				Name:   b.Name,
+				Filter: f,
+			})
+		}
+		return BucketAggregate(bs...), nil
+
+	case *pb.Aggregate_Metric_:
+		switch a.Metric.Type {
+		case pb.Aggregate_Metric_MAX:
+			return MaxAggregate(a.Metric.Field), nil
+
+		case pb.Aggregate_Metric_MIN:
+			return MinAggregate(a.Metric.Field), nil
+
+		case pb.Aggregate_Metric_AVG:
+			return AvgAggregate(a.Metric.Field), nil
+
+		case pb.Aggregate_Metric_SUM:
+			return SumAggregate(a.Metric.Field), nil
+		}
+		return nil, fmt.Errorf("unknown metric aggregate type: %v", a.Metric.Type)
+	}
+	return nil, fmt.Errorf("unexpected aggregate type: %T", pa.Aggregate)
+}
+
+func sortFromProto(ps *pb.Sort) (Sort, error) {
+	switch s := ps.Type.(type) {
+	case *pb.Sort_Field:
+		if ps.Order == pb.Sort_DESC {
+			return SortByField("-" + s.Field), nil
+		}
+		return SortByField(s.Field), nil
+	}
+	return nil, fmt.Errorf("unsupported sort type: %T", ps.Type)
+}
+
+// MarshalRequestJSON returns the JSON encoding of the request r.
+func MarshalRequestJSON(r Request) ([]byte, error) {
+	pr, err := r.proto()
+	if err != nil {
+		return nil, err
+	}
+	return marshalJSON(pr)
+}
+
+// UnmarshalRequestJSON parses the JSON encoding of a request.  Free text in the
+// request is returned in IndexQuery.Body.
+func UnmarshalRequestJSON(b []byte) (*Request, error) {
+	pr := &apipb.SearchRequest{}
+	if err := jsonpb.Unmarshal(bytes.NewReader(b), pr); err != nil {
+		return nil, err
+	}
+	return requestFromProto(pr)
+}
+
+func requestFromProto(pr *apipb.SearchRequest) (*Request, error) {
+	r := &Request{}
+	if pr.Tracking != nil {
+		t, err := trackingFromProto(pr.Tracking)
+		if err != nil {
+			return nil, err
+		}
+		r.Tracking = t
+	}
+
+	sr := pr.SearchRequest
+	if sr == nil {
+		return r, nil
+	}
+
+	r.Offset = int(sr.Offset)
+	r.Limit = int(sr.Limit)
+	r.Fields = sr.Fields
+
+	if sr.Filter != nil {
+		f, err := filterFromProto(sr.Filter)
+		if err != nil {
+			return nil, err
+		}
+		r.Filter = f
+	}
+
+	if sr.IndexQuery != nil {
+		iq, err := indexQueryFromProto(sr.IndexQuery)
+		if err != nil {
+			return nil, err
+		}
+		r.IndexQuery = iq
+	}
+
+	if sr.FeatureQuery != nil {
+		for _, x := range sr.FeatureQuery.FieldBoosts {
+			if x.FieldBoost == nil {
+				return nil, fmt.Errorf("feature field boost has no field boost")
+			}
+			fb, err := fieldBoostFromProto(x.FieldBoost)
+			if err != nil {
+				return nil, err
+			}
+			r.FeatureQuery.FieldBoosts = append(r.FeatureQuery.FieldBoosts, NewFeatureFieldBoost(fb, x.Value))
+		}
+	}
+
+	for _, x := range sr.Sort {
+		s, err := sortFromProto(x)
+		if err != nil {
+			return nil, err
+		}
+		r.Sort = append(r.Sort, s)
+	}
+
+	if len(sr.Aggregates) > 0 {
+		r.Aggregates = make(map[string]Aggregate, len(sr.Aggregates))
+		for k, v := range sr.Aggregates {
+			a, err := aggregateFromProto(v)
+			if err != nil {
+				return nil, err
+			}
+			r.Aggregates[k] = a
+		}
+	}
+
+	for _, t := range sr.Transforms {
+		r.Transforms = append(r.Transforms, Transform(t.Identifier))
+	}
+	return r, nil
+}
+
+func trackingFromProto(pt *apipb.SearchRequest_Tracking) (Tracking, error) {
+	var ty TrackingType
+	switch pt.Type {
+	case apipb.SearchRequest_Tracking_NONE:
+		ty = TrackingNone
+
+	case apipb.SearchRequest_Tracking_CLICK:
+		ty = TrackingClick
+
+	case apipb.SearchRequest_Tracking_POS_NEG:
+		ty = TrackingPosNeg
+
+	default:
+		return Tracking{}, fmt.Errorf("unknown tracking type: %v", pt.Type)
+	}
+
+	return Tracking{
+		Type:     ty,
+		QueryID:  pt.QueryId,
+		Sequence: int(pt.Sequence),
+		Field:    pt.Field,
+		Data:     pt.Data,
+	}, nil
+}
+
+func indexQueryFromProto(piq *pb.SearchRequest_IndexQuery) (IndexQuery, error) {
+	iq := IndexQuery{}
+	for _, b := range piq.Body {
+		iq.Body = append(iq.Body, Body{
+			Text:   b.Text,
+			Weight: b.Weight,
+		})
+	}
+
+	for _, t := range piq.Terms {
+		iq.Terms = append(iq.Terms, Term{
+			Value:  t.Value,
+			Field:  t.Field,
+			Pos:    uint16(t.Pos),
+			Neg:    uint16(t.Neg),
+			Weight: t.Weight,
+			WOff:   uint16(t.WordOffset),
+			POff:   uint16(t.ParaOffset),
+		})
+	}
+
+	for _, x := range piq.FieldBoosts {
+		fb, err := fieldBoostFromProto(x)
+		if err != nil {
+			return IndexQuery{}, err
+		}
+		iq.FieldBoosts = append(iq.FieldBoosts, fb)
+	}
+
+	for _, x := range piq.InstanceBoosts {
+		ib, err := instanceBoostFromProto(x)
+		if err != nil {
+			return IndexQuery{}, err
+		}
+		iq.InstanceBoosts = append(iq.InstanceBoosts, ib)
+	}
+	return iq, nil
+}
+
+func fieldBoostFromProto(pfb *pb.FieldBoost) (FieldBoost, error) {
+	switch b := pfb.FieldBoost.(type) {
+	case *pb.FieldBoost_Filter_:
+		if b.Filter.Filter == nil {
+			return nil, fmt.Errorf("filter field boost has no filter")
+		}
+		f, err := filterFromProto(b.Filter.Filter)
+		if err != nil {
+			return nil, err
+		}
+		return FilterFieldBoost(f, b.Filter.Value), nil
+
+	case *pb.FieldBoost_Interval_:
+		points := make([]IntervalPoint, 0, len(b.Interval.Points))
+		for _, p := range b.Interval.Points {
+			points = append(points, IntervalPoint{
+				Point: p.Point,
+				Value: p.Value,
+			})
+		}
+		return IntervalFieldBoost(b.Interval.Field, points...), nil
+
+	case *pb.FieldBoost_Element_:
+		return ElementFieldBoost(b.Element.Field, b.Element.Elts), nil
+
+	case *pb.FieldBoost_Text_:
+		return TextFieldBoost(b.Text.Field, b.Text.Text), nil
+	}
+	return nil, fmt.Errorf("unexpected field boost type: %T", pfb.FieldBoost)
+}
+
+func instanceBoostFromProto(pib *pb.InstanceBoost) (InstanceBoost, error) {
+	switch b := pib.InstanceBoost.(type) {
+	case *pb.InstanceBoost_Field_:
+		return FieldInstanceBoost(b.Field.Field, b.Field.Value), nil
+
+	case *pb.InstanceBoost_Score_:
+		return ScoreInstanceBoost(int(b.Score.MinCount), b.Score.Threshold), nil
+	}
+	return nil, fmt.Errorf("unexpected instance boost type: %T", pib.InstanceBoost)
+}
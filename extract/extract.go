@@ -0,0 +1,348 @@
+// Package extract converts HTML documents into text and structured fields suitable for
+// records (see sajari.NewRecord), so that crawler-style integrations share one extraction
+// path.
+package extract
+
+import (
+	"io"
+	"strings"
+	"unicode"
+
+	"golang.org/x/net/html"
+	"golang.org/x/net/html/atom"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// Names of the fields set by Document.Record.
+const (
+	TitleField       = "title"
+	DescriptionField = "description"
+	KeywordsField    = "keywords"
+	HeadingsField    = "headings"
+)
+
+// SchemaFields are schema definitions for the fields set by Document.Record, for use
+// with sajari.Schema.Add.
+var SchemaFields = []sajari.Field{
+	{
+		Name:        TitleField,
+		Description: "Title of the page.",
+		Type:        sajari.TypeString,
+		Indexed:     true,
+	},
+	{
+		Name:        DescriptionField,
+		Description: "Meta description of the page.",
+		Type:        sajari.TypeString,
+		Indexed:     true,
+	},
+	{
+		Name:        KeywordsField,
+		Description: "Meta keywords of the page.",
+		Type:        sajari.TypeString,
+		Repeated:    true,
+	},
+	{
+		Name:        HeadingsField,
+		Description: "Text of the headings on the page.",
+		Type:        sajari.TypeString,
+		Repeated:    true,
+		Indexed:     true,
+	},
+}
+
+// Document is the content extracted from an HTML document.
+type Document struct {
+	// Title is the text of the <title> element, or the og:title meta property if
+	// there is no title.
+	Title string
+
+	// Description is the content of the description meta tag, or the og:description
+	// meta property if there is no description.
+	Description string
+
+	// Keywords are the comma separated values of the keywords meta tag.
+	Keywords []string
+
+	// Headings are the text of the <h1> to <h6> elements, in document order.
+	Headings []string
+
+	// Body is the text of the document body, with one line per paragraph (or other
+	// block element).  Scripts, styles, navigation and hidden elements are excluded.
+	Body string
+}
+
+// Record returns a record with the Body of d and its fields (see TitleField etc.).  Empty
+// fields are not set.
+func (d *Document) Record() sajari.Record {
+	values := make(map[string]interface{}, 4)
+	if d.Title != "" {
+		values[TitleField] = d.Title
+	}
+	if d.Description != "" {
+		values[DescriptionField] = d.Description
+	}
+	if len(d.Keywords) > 0 {
+		values[KeywordsField] = d.Keywords
+	}
+	if len(d.Headings) > 0 {
+		values[HeadingsField] = d.Headings
+	}
+	return sajari.NewRecord(d.Body, values)
+}
+
+// skipped are elements whose content is not part of the document text.
+var skipped = map[atom.Atom]bool{
+	atom.Head:     true,
+	atom.Script:   true,
+	atom.Style:    true,
+	atom.Noscript: true,
+	atom.Template: true,
+	atom.Svg:      true,
+	atom.Iframe:   true,
+	atom.Object:   true,
+	atom.Nav:      true,
+	atom.Button:   true,
+	atom.Select:   true,
+}
+
+// blocks are elements which start a new line of body text.
+var blocks = map[atom.Atom]bool{
+	atom.Address: true, atom.Article: true, atom.Aside: true, atom.Blockquote: true,
+	atom.Br: true, atom.Dd: true, atom.Div: true, atom.Dl: true, atom.Dt: true,
+	atom.Fieldset: true, atom.Figcaption: true, atom.Figure: true, atom.Footer: true,
+	atom.Form: true, atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true,
+	atom.H5: true, atom.H6: true, atom.Header: true, atom.Hr: true, atom.Li: true,
+	atom.Main: true, atom.Ol: true, atom.P: true, atom.Pre: true, atom.Section: true,
+	atom.Table: true, atom.Td: true, atom.Th: true, atom.Tr: true, atom.Ul: true,
+}
+
+var headings = map[atom.Atom]bool{
+	atom.H1: true, atom.H2: true, atom.H3: true, atom.H4: true, atom.H5: true, atom.H6: true,
+}
+
+// HTML parses the HTML document read from r, which must be UTF-8 encoded (use
+// golang.org/x/net/html/charset to convert other encodings).
+func HTML(r io.Reader) (*Document, error) {
+	root, err := html.Parse(r)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &extractor{}
+	e.head(root)
+	e.body(root)
+	e.newLine()
+
+	d := &Document{
+		Title:       e.title,
+		Description: e.description,
+		Keywords:    e.keywords,
+		Headings:    e.headings,
+		Body:        strings.Join(e.lines, "\n"),
+	}
+	if d.Title == "" {
+		d.Title = e.ogTitle
+	}
+	if d.Description == "" {
+		d.Description = e.ogDescription
+	}
+	return d, nil
+}
+
+type extractor struct {
+	title, ogTitle             string
+	description, ogDescription string
+	keywords                   []string
+	headings                   []string
+
+	lines []string
+	line  []string // text of the current line, joined without separators
+}
+
+// head collects the title and meta tags of the document rooted at n.
+func (e *extractor) head(n *html.Node) {
+	if n.Type == html.ElementNode {
+		switch n.DataAtom {
+		case atom.Title:
+			if e.title == "" {
+				e.title = Text(nodeText(n))
+			}
+			return
+
+		case atom.Meta:
+			e.meta(n)
+			return
+
+		case atom.Body:
+			return
+		}
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		e.head(c)
+	}
+}
+
+func (e *extractor) meta(n *html.Node) {
+	content := Text(attr(n, "content"))
+	if content == "" {
+		return
+	}
+
+	switch strings.ToLower(attr(n, "name")) {
+	case "description":
+		if e.description == "" {
+			e.description = content
+		}
+		return
+
+	case "keywords":
+		if e.keywords == nil {
+			for _, k := range strings.Split(content, ",") {
+				if k = strings.TrimSpace(k); k != "" {
+					e.keywords = append(e.keywords, k)
+				}
+			}
+		}
+		return
+	}
+
+	switch strings.ToLower(attr(n, "property")) {
+	case "og:title":
+		if e.ogTitle == "" {
+			e.ogTitle = content
+		}
+
+	case "og:description":
+		if e.ogDescription == "" {
+			e.ogDescription = content
+		}
+	}
+}
+
+// body collects the text and headings of the document rooted at n.
+func (e *extractor) body(n *html.Node) {
+	switch n.Type {
+	case html.TextNode:
+		// Text is joined as-is, so that inline markup within words doesn't split them.
+		// Whitespace is collapsed when the line ends.
+		e.line = append(e.line, n.Data)
+		return
+
+	case html.ElementNode:
+		if skipped[n.DataAtom] || hidden(n) {
+			return
+		}
+		if headings[n.DataAtom] {
+			if h := Text(subtreeText(n)); h != "" {
+				e.headings = append(e.headings, h)
+			}
+		}
+	}
+
+	block := n.Type == html.ElementNode && blocks[n.DataAtom]
+	if block {
+		e.newLine()
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		e.body(c)
+	}
+	if block {
+		e.newLine()
+	}
+}
+
+// newLine ends the current line of body text.
+func (e *extractor) newLine() {
+	if s := Text(strings.Join(e.line, "")); s != "" {
+		e.lines = append(e.lines, s)
+	}
+	e.line = e.line[:0]
+}
+
+// hidden returns true if n is marked as hidden from users.
+func hidden(n *html.Node) bool {
+	for _, a := range n.Attr {
+		switch strings.ToLower(a.Key) {
+		case "hidden":
+			return true
+
+		case "aria-hidden":
+			if strings.EqualFold(a.Val, "true") {
+				return true
+			}
+
+		case "style":
+			s := strings.ToLower(strings.Replace(a.Val, " ", "", -1))
+			if strings.Contains(s, "display:none") || strings.Contains(s, "visibility:hidden") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// nodeText returns the concatenated text of the children of n.
+func nodeText(n *html.Node) string {
+	var parts []string
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if c.Type == html.TextNode {
+			parts = append(parts, c.Data)
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// subtreeText returns the text of all text nodes below n.  Text is only separated where
+// the document has whitespace or block elements.
+func subtreeText(n *html.Node) string {
+	var parts []string
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			parts = append(parts, n.Data)
+			return
+		}
+		if n.Type == html.ElementNode {
+			if skipped[n.DataAtom] || hidden(n) {
+				return
+			}
+			if blocks[n.DataAtom] {
+				parts = append(parts, " ")
+				defer func() { parts = append(parts, " ") }()
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return strings.Join(parts, "")
+}
+
+// attr returns the value of the attribute key of n.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if strings.EqualFold(a.Key, key) {
+			return a.Val
+		}
+	}
+	return ""
+}
+
+// Text cleans the plain text s: control characters and invalid UTF-8 are removed, runs of
+// whitespace are replaced with a single space and leading and trailing whitespace is
+// trimmed.
+func Text(s string) string {
+	s = strings.Map(func(r rune) rune {
+		if unicode.IsControl(r) && !unicode.IsSpace(r) {
+			return -1
+		}
+		if r == unicode.ReplacementChar {
+			return -1
+		}
+		return r
+	}, s)
+	return strings.Join(strings.Fields(s), " ")
+}
@@ -0,0 +1,126 @@
+package extract
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+func TestHTMLBody(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want string
+	}{
+		{
+			name: "inline markup within a word",
+			html: `<p>Hel<b>lo</b> <a href="/">Sajari</a>'s</p>`,
+			want: "Hello Sajari's",
+		},
+		{
+			name: "whitespace between inline elements",
+			html: "<p><em>one</em>\n  <strong>two</strong></p>",
+			want: "one two",
+		},
+		{
+			name: "block elements start new lines",
+			html: `<div>first</div><p>second <span>line</span></p>third<br>fourth`,
+			want: "first\nsecond line\nthird\nfourth",
+		},
+		{
+			name: "list items and table cells",
+			html: `<ul><li>a</li><li>b</li></ul><table><tr><td>c</td><td>d</td></tr></table>`,
+			want: "a\nb\nc\nd",
+		},
+		{
+			name: "skipped and hidden elements",
+			html: `<nav>Menu</nav><p>vis<script>var x</script>ible</p><div hidden>secret</div>` +
+				`<span aria-hidden="true">icon</span><p style="display: none">none</p>`,
+			want: "visible",
+		},
+		{
+			name: "control characters",
+			html: "<p>a\x00b\u0007c</p>",
+			want: "abc",
+		},
+	}
+
+	for _, tt := range tests {
+		d, err := HTML(strings.NewReader(tt.html))
+		if err != nil {
+			t.Errorf("%v: HTML() error: %v", tt.name, err)
+			continue
+		}
+		if d.Body != tt.want {
+			t.Errorf("%v: Body = %q, want %q", tt.name, d.Body, tt.want)
+		}
+	}
+}
+
+func TestHTMLHeadings(t *testing.T) {
+	d, err := HTML(strings.NewReader(`<h1>Hel<i>lo</i> World</h1><h2>One<br>Two</h2><h3 hidden>Hidden</h3><h3>  </h3>`))
+	if err != nil {
+		t.Fatalf("HTML() error: %v", err)
+	}
+	want := []string{"Hello World", "One Two"}
+	if !reflect.DeepEqual(d.Headings, want) {
+		t.Errorf("Headings = %q, want %q", d.Headings, want)
+	}
+}
+
+func TestHTMLHead(t *testing.T) {
+	tests := []struct {
+		name string
+		html string
+		want Document
+	}{
+		{
+			name: "title and meta tags",
+			html: `<head><title> Hello
+				World </title><meta name="Description" content="A  page">` +
+				`<meta name="keywords" content="a, b,,c"></head>`,
+			want: Document{
+				Title:       "Hello World",
+				Description: "A page",
+				Keywords:    []string{"a", "b", "c"},
+			},
+		},
+		{
+			name: "open graph fallbacks",
+			html: `<head><meta property="og:title" content="OG Title">` +
+				`<meta property="og:description" content="OG description"></head>`,
+			want: Document{
+				Title:       "OG Title",
+				Description: "OG description",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		d, err := HTML(strings.NewReader(tt.html))
+		if err != nil {
+			t.Errorf("%v: HTML() error: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(*d, tt.want) {
+			t.Errorf("%v: HTML() = %+v, want %+v", tt.name, *d, tt.want)
+		}
+	}
+}
+
+func TestDocumentRecord(t *testing.T) {
+	d := &Document{
+		Title:    "Title",
+		Headings: []string{"Heading"},
+		Body:     "Body",
+	}
+	r := d.Record()
+	if r[sajari.BodyField] != "Body" || r[TitleField] != "Title" || !reflect.DeepEqual(r[HeadingsField], []string{"Heading"}) {
+		t.Errorf("Record() = %v", r)
+	}
+	if _, ok := r[DescriptionField]; ok {
+		t.Errorf("Record() sets empty %v: %v", DescriptionField, r)
+	}
+}
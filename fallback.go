@@ -0,0 +1,106 @@
+package sajari
+
+import (
+	"golang.org/x/net/context"
+)
+
+// Relaxation is a step used by SearchWithFallback to relax a Request which returned no
+// results.
+type Relaxation struct {
+	// Name identifies the relaxation, and is reported by SearchWithFallback when the
+	// relaxation has been applied.
+	Name string
+
+	// Relax modifies the request r, returning false if the relaxation does not apply to
+	// r (and so r was not modified).  The request is a copy of the caller's Request
+	// (see Request.Clone).
+	Relax func(ctx context.Context, r *Request) (bool, error)
+}
+
+// DropFilter is a Relaxation which removes the filter from the request.
+var DropFilter = Relaxation{
+	Name: "drop-filter",
+	Relax: func(ctx context.Context, r *Request) (bool, error) {
+		if r.Filter == nil {
+			return false, nil
+		}
+		r.Filter = nil
+		return true, nil
+	},
+}
+
+// DropFieldBoosts is a Relaxation which removes field and instance boosts from the
+// index query.
+var DropFieldBoosts = Relaxation{
+	Name: "drop-field-boosts",
+	Relax: func(ctx context.Context, r *Request) (bool, error) {
+		if len(r.IndexQuery.FieldBoosts) == 0 && len(r.IndexQuery.InstanceBoosts) == 0 {
+			return false, nil
+		}
+		r.IndexQuery.FieldBoosts = nil
+		r.IndexQuery.InstanceBoosts = nil
+		return true, nil
+	},
+}
+
+// SpellingCorrection returns a Relaxation which replaces the query text (IndexQuery.Text)
+// with the result of calling correct, for instance using the first suggestion from an
+// autocomplete model (see the autocomplete package).  The relaxation does not apply if
+// the query text is empty, or correct returns the empty string or the unchanged text.
+func SpellingCorrection(correct func(ctx context.Context, text string) (string, error)) Relaxation {
+	return Relaxation{
+		Name: "spelling-correction",
+		Relax: func(ctx context.Context, r *Request) (bool, error) {
+			if r.IndexQuery.Text == "" {
+				return false, nil
+			}
+			text, err := correct(ctx, r.IndexQuery.Text)
+			if err != nil {
+				return false, err
+			}
+			if text == "" || text == r.IndexQuery.Text {
+				return false, nil
+			}
+			r.IndexQuery.Text = text
+			return true, nil
+		},
+	}
+}
+
+// SearchWithFallback runs a search like Search, but if there are no results then the request
+// is relaxed using each of the relaxations rs in turn, searching again after each one which
+// applies, until results are found or the relaxations are exhausted.  Relaxations are
+// cumulative: each is applied to the request produced by the previous ones.
+//
+// Returns the results of the last search run, and the names of the relaxations which were
+// applied to produce them (empty if the original request returned results).  The Request r
+// is not modified.
+func (q *Query) SearchWithFallback(ctx context.Context, r *Request, rs ...Relaxation) (*Results, []string, error) {
+	results, err := q.Search(ctx, r)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var applied []string
+	rc := r.Clone()
+	for _, rx := range rs {
+		if len(results.Results) > 0 {
+			break
+		}
+
+		ok, err := rx.Relax(ctx, &rc)
+		if err != nil {
+			return nil, applied, err
+		}
+		if !ok {
+			continue
+		}
+		applied = append(applied, rx.Name)
+
+		results, err = q.Search(ctx, &rc)
+		if err != nil {
+			return nil, applied, err
+		}
+	}
+	return results, applied, nil
+}
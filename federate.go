@@ -0,0 +1,143 @@
+package sajari
+
+import (
+	"sort"
+	"sync"
+
+	"golang.org/x/net/context"
+)
+
+// Federate returns a Federation which searches the collections of each of the clients.
+func Federate(clients ...*Client) *Federation {
+	return &Federation{
+		clients: clients,
+	}
+}
+
+// Federation runs searches across several collections, merging the results.  Use Federate
+// to create one.  It is safe for concurrent use.
+type Federation struct {
+	clients []*Client
+}
+
+// FederatedResults is the merged results of a federated search.
+type FederatedResults struct {
+	// TotalResults is the total number of results across all collections.
+	TotalResults int
+
+	// Results are the merged results, ordered by normalised score.
+	Results []FederatedResult
+
+	// Collections are the results from each collection, in the order of the clients
+	// passed to Federate.  The results for a collection which returned an error are nil.
+	Collections []*Results
+}
+
+// FederatedResult is an individual result from a federated search.
+type FederatedResult struct {
+	Result
+
+	// Client is the index of the client (as passed to Federate) which returned the result.
+	Client int
+
+	// Collection is the collection which the result is from.
+	Collection string
+
+	// NormalisedScore is the score of the result divided by the highest score in its
+	// collection's results, so that scores from different collections can be compared.
+	// The best result from each collection has a normalised score of 1.
+	NormalisedScore float64
+}
+
+// defaultFederatedLimit is the number of merged results returned by Federation.Search
+// when Request.Limit is 0.
+const defaultFederatedLimit = 10
+
+// Search runs the Request r against each collection concurrently, and merges the results by
+// normalised score.  Offset and Limit are applied to the merged results, so each collection is
+// asked for the first Offset+Limit results.  If Limit is 0 then 10 results are returned.
+// Aggregates are not merged, and are available from FederatedResults.Collections.
+//
+// Merged results are always ordered by normalised score.  Sort is passed on to each
+// collection, so it decides which results each collection returns, but is not used to
+// order the merged results: use FederatedResults.Collections for results in Sort order.
+// Scores are normalised against the best result in each collection, so every
+// collection's best result has a normalised score of 1, however weak a match it is.
+//
+// If any of the searches fail then a MultiError is returned with errors set in the indexes of
+// the respective clients, along with the merged results of the successful searches.
+func (f *Federation) Search(ctx context.Context, r *Request) (*FederatedResults, error) {
+	limit := r.Limit
+	if limit <= 0 {
+		limit = defaultFederatedLimit
+	}
+
+	rc := *r
+	rc.Offset = 0
+	rc.Limit = r.Offset + limit
+
+	out := &FederatedResults{
+		Collections: make([]*Results, len(f.clients)),
+	}
+	errs := make(MultiError, len(f.clients))
+
+	var wg sync.WaitGroup
+	for i, c := range f.clients {
+		wg.Add(1)
+		go func(i int, c *Client) {
+			defer wg.Done()
+			out.Collections[i], errs[i] = c.Query().Search(ctx, &rc)
+		}(i, c)
+	}
+	wg.Wait()
+
+	var merged federatedResults
+	failed := false
+	for i, rs := range out.Collections {
+		if errs[i] != nil {
+			failed = true
+			continue
+		}
+		out.TotalResults += rs.TotalResults
+
+		max := 0.0
+		for _, res := range rs.Results {
+			if res.Score > max {
+				max = res.Score
+			}
+		}
+		for _, res := range rs.Results {
+			fr := FederatedResult{
+				Result:     res,
+				Client:     i,
+				Collection: f.clients[i].Collection,
+			}
+			if max > 0 {
+				fr.NormalisedScore = res.Score / max
+			}
+			merged = append(merged, fr)
+		}
+	}
+	sort.Stable(merged)
+
+	if r.Offset < len(merged) {
+		merged = merged[r.Offset:]
+	} else {
+		merged = nil
+	}
+	if limit < len(merged) {
+		merged = merged[:limit]
+	}
+	out.Results = merged
+
+	if failed {
+		return out, errs
+	}
+	return out, nil
+}
+
+type federatedResults []FederatedResult
+
+func (fr federatedResults) Len() int           { return len(fr) }
+func (fr federatedResults) Less(i, j int) bool { return fr[i].NormalisedScore > fr[j].NormalisedScore }
+func (fr federatedResults) Swap(i, j int)      { fr[i], fr[j] = fr[j], fr[i] }
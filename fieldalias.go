@@ -0,0 +1,120 @@
+package sajari
+
+import (
+	"fmt"
+	"strings"
+
+	enginepb "code.sajari.com/protogen-go/sajari/engine"
+)
+
+// fieldAliases holds the keys used to return each requested field, as set by entries
+// of the form "field AS alias" in Request.Fields.
+type fieldAliases map[string][]string
+
+// parseFields splits the entries of Request.Fields into the field names to request from
+// the engine and the aliases to apply to result values.  The returned aliases are nil if
+// no entry uses an alias.
+func parseFields(fields []string) ([]string, fieldAliases, error) {
+	var names []string
+	var aliases fieldAliases
+	seen := make(map[string]bool, len(fields))
+	for i, f := range fields {
+		name, alias, err := parseField(f)
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if alias != name && aliases == nil {
+			aliases = make(fieldAliases, len(fields))
+			for _, prev := range fields[:i] {
+				// Entries before i have already been validated.
+				n, a, _ := parseField(prev)
+				aliases[n] = append(aliases[n], a)
+			}
+		}
+		if aliases != nil {
+			aliases[name] = append(aliases[name], alias)
+		}
+
+		if !seen[name] {
+			seen[name] = true
+			names = append(names, name)
+		}
+	}
+
+	if aliases == nil {
+		return names, nil, nil
+	}
+
+	keys := make(map[string]string, len(fields))
+	for name, as := range aliases {
+		for _, a := range as {
+			if prev, ok := keys[a]; ok && prev != name {
+				return nil, nil, fmt.Errorf("fields %q and %q are both returned as %q", prev, name, a)
+			}
+			keys[a] = name
+		}
+	}
+	return names, aliases, nil
+}
+
+// fieldKeys returns the keys of result values returned for the entries of Request.Fields,
+// which are the aliases of entries of the form "field AS alias".
+func fieldKeys(fields []string) ([]string, error) {
+	out := make([]string, 0, len(fields))
+	for _, f := range fields {
+		_, alias, err := parseField(f)
+		if err != nil {
+			return nil, err
+		}
+		out = append(out, alias)
+	}
+	return out, nil
+}
+
+// parseField parses a single entry of Request.Fields, which is either a field name or
+// of the form "field AS alias" (AS is case-insensitive).
+func parseField(f string) (name, alias string, err error) {
+	parts := strings.Fields(f)
+	switch {
+	case len(parts) == 1:
+		return parts[0], parts[0], nil
+
+	case len(parts) == 3 && strings.EqualFold(parts[1], "AS"):
+		return parts[0], parts[2], nil
+	}
+	return "", "", fmt.Errorf("invalid field %q: expected field name or \"field AS alias\"", f)
+}
+
+// field returns the name of the field returned with key k, if k is an alias.
+func (fa fieldAliases) field(k string) (string, bool) {
+	for name, as := range fa {
+		for _, a := range as {
+			if a == k {
+				return name, true
+			}
+		}
+	}
+	return "", false
+}
+
+// apply returns values with keys renamed by the aliases.  Values of fields which were not
+// requested are left unchanged.
+func (fa fieldAliases) apply(values map[string]*enginepb.Value) map[string]*enginepb.Value {
+	if fa == nil {
+		return values
+	}
+
+	out := make(map[string]*enginepb.Value, len(values))
+	for k, v := range values {
+		as, ok := fa[k]
+		if !ok {
+			out[k] = v
+			continue
+		}
+		for _, a := range as {
+			out[a] = v
+		}
+	}
+	return out
+}
@@ -1,6 +1,12 @@
 package sajari
 
-import pb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+import (
+	"fmt"
+	"math"
+	"sort"
+
+	pb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+)
 
 type fieldBoosts []FieldBoost
 
@@ -160,6 +166,43 @@ func ElementFieldBoost(field string, elts []string) FieldBoost {
 	}
 }
 
+// WeightedElementFieldBoosts returns boosts for the repeated field which apply the weight
+// of each element in elts to records where the field contains that element, so that
+// weighted preferences (e.g. a user's affinity for categories) can be expressed directly.
+// Weights must be positive.
+//
+// The engine does not support per-element weights in ElementFieldBoost, so one
+// FilterFieldBoost (using the ~ "contains" operator) is returned for each element, in
+// sorted order.  Add them all to IndexQuery.FieldBoosts:
+//
+//	q.FieldBoosts = append(q.FieldBoosts, sajari.WeightedElementFieldBoosts("categories", map[string]float64{
+//		"shoes": 0.8,
+//		"hats":  0.2,
+//	})...)
+//
+// Scoring differs from ElementFieldBoost: rather than a single boost of the proportion of
+// elts found in the field, each matching element contributes its own boost, and the boosts
+// of all the elements a record contains are added together.  A record containing both "shoes"
+// and "hats" above receives both boosts, and records containing none receive neither.
+func WeightedElementFieldBoosts(field string, elts map[string]float64) []FieldBoost {
+	keys := make([]string, 0, len(elts))
+	for k := range elts {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	out := make([]FieldBoost, 0, len(keys))
+	for _, k := range keys {
+		w := elts[k]
+		if !(w > 0) || math.IsInf(w, 0) {
+			out = append(out, invalidFieldBoost{fmt.Errorf("element boost on %q: weight of %q must be positive, got %v", field, k, w)})
+			continue
+		}
+		out = append(out, FilterFieldBoost(FieldFilter(field+" ~", k), w))
+	}
+	return out
+}
+
 type elementFieldBoost struct {
 	field string   // Field containing stringArray.
 	elts  []string // List of elements to match against.
@@ -0,0 +1,68 @@
+package sajari
+
+import (
+	"fmt"
+
+	"golang.org/x/net/context"
+)
+
+// FieldUsage describes how a field is used by the records in a collection.
+type FieldUsage struct {
+	Field
+
+	// Values is the number of values of the field across all records.  For fields which
+	// are not repeated this is the number of records which have the field set.
+	Values int
+
+	// Distinct is the number of distinct values of the field.  This is approximate, as the
+	// engine may limit the number of values counted.
+	Distinct int
+}
+
+// FieldUsage returns usage statistics for each field in the collection, and the total
+// number of records in the collection.  Use this to find fields which are unused, or which
+// are candidates for being made unique.
+//
+// Statistics are computed using a count aggregate on every field in a single search (see
+// CountAggregate), which can be expensive for large collections with many distinct values.
+func (s *Schema) FieldUsage(ctx context.Context) ([]FieldUsage, int, error) {
+	fs, err := s.Fields(ctx)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	aggs := make(map[string]Aggregate, len(fs))
+	for _, f := range fs {
+		aggs[f.Name] = CountAggregate(f.Name)
+	}
+
+	resp, err := s.c.Query().Search(SkipQueryCache(ctx), &Request{
+		Aggregates:  aggs,
+		SkipResults: true,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	out := make([]FieldUsage, 0, len(fs))
+	for _, f := range fs {
+		u := FieldUsage{
+			Field: f,
+		}
+
+		switch v := resp.Aggregates[f.Name].(type) {
+		case nil:
+
+		case CountResponse:
+			u.Distinct = len(v)
+			for _, n := range v {
+				u.Values += n
+			}
+
+		default:
+			return nil, 0, fmt.Errorf("sajari: field %q: unexpected aggregate response %T", f.Name, v)
+		}
+		out = append(out, u)
+	}
+	return out, resp.TotalResults, nil
+}
@@ -0,0 +1,76 @@
+package sajari
+
+import (
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// WithHedging configures the client to hedge searches: if a search hasn't completed
+// within delay then another identical request is made, up to maxAttempts requests in
+// total, and the first successful response is used.  Outstanding requests are
+// cancelled once a response is received.  This reduces tail latency at the cost of
+// extra load on the engine.
+//
+// Hedging applies to the read-only Query.Search, Query.SearchInto, Pipeline.Search and
+// Pipeline.SearchInto calls.  A maxAttempts of 1 or less disables hedging.
+func WithHedging(delay time.Duration, maxAttempts int) Opt {
+	return func(c *Client) {
+		c.hedgeDelay = delay
+		c.hedgeAttempts = maxAttempts
+	}
+}
+
+type hedgeResult struct {
+	v   interface{}
+	err error
+}
+
+// hedge calls fn, making additional attempts if hedging is enabled (see WithHedging).
+// Returns the first successful result, or the last error if all attempts fail.
+func (c *Client) hedge(ctx context.Context, fn func(context.Context) (interface{}, error)) (interface{}, error) {
+	if c.hedgeAttempts <= 1 {
+		return fn(ctx)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	results := make(chan hedgeResult, c.hedgeAttempts)
+	attempt := func() {
+		v, err := fn(ctx)
+		results <- hedgeResult{v, err}
+	}
+
+	go attempt()
+	attempts, inflight := 1, 1
+
+	t := time.NewTimer(c.hedgeDelay)
+	defer t.Stop()
+
+	var err error
+	for {
+		select {
+		case r := <-results:
+			inflight--
+			if r.err == nil {
+				return r.v, nil
+			}
+			err = r.err
+			if inflight == 0 {
+				return nil, err
+			}
+
+		case <-t.C:
+			if attempts < c.hedgeAttempts {
+				go attempt()
+				attempts++
+				inflight++
+				t.Reset(c.hedgeDelay)
+			}
+
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,93 @@
+package sajari
+
+// QueryHook is a function which is run on each Request before it is searched.  Hooks can
+// modify the request (e.g. to add a mandatory filter), or return an error to prevent the
+// search from running.
+type QueryHook func(r *Request) error
+
+// PipelineHook is a function which is run on the values passed to each pipeline search
+// before it is run.  Hooks can modify the values, or return an error to prevent the search
+// from running.
+type PipelineHook func(values map[string]string) error
+
+// ResultHook is a function which is run on the Results of each search before they are
+// returned.  Hooks can inspect or modify the results (e.g. to remove sensitive values), or
+// return an error which is returned in place of the results.
+type ResultHook func(rs *Results) error
+
+// WithQueryHook configures the client to run h on the Request passed to each call to
+// Query.Search and Query.SearchInto.  Hooks are run in the order in which they are added.
+//
+// Hooks are given a copy of the Request (see Request.Clone), so the caller's Request is not
+// changed.
+func WithQueryHook(h QueryHook) Opt {
+	return func(c *Client) {
+		c.queryHooks = append(c.queryHooks, h)
+	}
+}
+
+// WithPipelineHook configures the client to run h on the values passed to each call to
+// Pipeline.Search and Pipeline.SearchInto.  Hooks are run in the order in which they are
+// added, and are given a copy of the values.
+func WithPipelineHook(h PipelineHook) Opt {
+	return func(c *Client) {
+		c.pipelineHooks = append(c.pipelineHooks, h)
+	}
+}
+
+// WithResultHook configures the client to run h on the Results of each call to
+// Query.Search, Query.SearchInto, Pipeline.Search and Pipeline.SearchInto.  Hooks are run
+// in the order in which they are added.
+//
+// Results returned by SearchInto do not have Result.Values set (the values are decoded
+// into the destination instead).
+func WithResultHook(h ResultHook) Opt {
+	return func(c *Client) {
+		c.resultHooks = append(c.resultHooks, h)
+	}
+}
+
+// runQueryHooks returns the result of running the query hooks on a copy of r.  If no hooks
+// are configured then r is returned.
+func (c *Client) runQueryHooks(r *Request) (*Request, error) {
+	if len(c.queryHooks) == 0 {
+		return r, nil
+	}
+
+	rc := r.Clone()
+	for _, h := range c.queryHooks {
+		if err := h(&rc); err != nil {
+			return nil, err
+		}
+	}
+	return &rc, nil
+}
+
+// runPipelineHooks returns the result of running the pipeline hooks on a copy of values.
+// If no hooks are configured then values is returned.
+func (c *Client) runPipelineHooks(values map[string]string) (map[string]string, error) {
+	if len(c.pipelineHooks) == 0 {
+		return values, nil
+	}
+
+	vc := make(map[string]string, len(values))
+	for k, v := range values {
+		vc[k] = v
+	}
+	for _, h := range c.pipelineHooks {
+		if err := h(vc); err != nil {
+			return nil, err
+		}
+	}
+	return vc, nil
+}
+
+// runResultHooks runs the result hooks on rs, returning rs or the first error.
+func (c *Client) runResultHooks(rs *Results) (*Results, error) {
+	for _, h := range c.resultHooks {
+		if err := h(rs); err != nil {
+			return nil, err
+		}
+	}
+	return rs, nil
+}
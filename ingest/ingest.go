@@ -0,0 +1,326 @@
+// Package ingest provides a consumer loop which feeds records from message queues (Kafka,
+// SQS, Pub/Sub etc.) into a collection.
+//
+// Messages are read from a Source, converted into records by a Handler and added in
+// batches using AddMulti.  Adds which fail with transient errors are retried, and
+// messages which can't be handled or added are passed to a dead letter callback.
+// Messages are only acknowledged once they have been added or dead-lettered, and are
+// acknowledged in the order they were received (so that queues which commit offsets never
+// commit past an earlier message), so delivery is at-least-once.
+package ingest
+
+import (
+	"fmt"
+	"io"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"code.sajari.com/sajari-sdk-go"
+	"code.sajari.com/sajari-sdk-go/internal/retryutil"
+)
+
+// Defaults used by Consumer when fields are not set.
+const (
+	DefaultBatchSize     = 100
+	DefaultFlushInterval = time.Second
+	DefaultRetries       = 3
+)
+
+// Message is a message read from a queue.
+type Message interface {
+	// Data returns the message payload.
+	Data() []byte
+
+	// Ack acknowledges the message, so that it is not delivered again.
+	Ack() error
+}
+
+// Source is a queue of messages.
+type Source interface {
+	// Receive returns the next message, blocking until one is available or ctx is done.
+	// Receive returns io.EOF when there are no more messages.
+	Receive(ctx context.Context) (Message, error)
+}
+
+// Handler converts messages into records.
+type Handler interface {
+	// Handle returns the record to add for m.  A nil record and nil error skips the
+	// message (it is acknowledged without adding a record).
+	Handle(ctx context.Context, m Message) (sajari.Record, error)
+}
+
+// HandlerFunc is an adapter which allows a function to be used as a Handler.
+type HandlerFunc func(ctx context.Context, m Message) (sajari.Record, error)
+
+// Handle implements Handler.
+func (f HandlerFunc) Handle(ctx context.Context, m Message) (sajari.Record, error) {
+	return f(ctx, m)
+}
+
+// JSONHandler is a Handler which decodes message payloads as JSON records (see
+// sajari.Record.UnmarshalJSON).
+var JSONHandler Handler = HandlerFunc(func(ctx context.Context, m Message) (sajari.Record, error) {
+	var r sajari.Record
+	if err := r.UnmarshalJSON(m.Data()); err != nil {
+		return nil, err
+	}
+	return r, nil
+})
+
+// DeadLetterFunc is called with messages which could not be handled or added, along with
+// the reason.  The message is acknowledged after the call returns.  Messages which could
+// not be handled are passed to the DeadLetterFunc once the messages received before them
+// have been added.
+type DeadLetterFunc func(ctx context.Context, m Message, err error)
+
+// New creates a new Consumer which reads messages from src, converts them into records
+// using h and adds them to the collection of client.  Set the Consumer fields to configure
+// batching, retries and dead-lettering before calling Run.
+func New(client *sajari.Client, src Source, h Handler) *Consumer {
+	return &Consumer{
+		c:       client,
+		src:     src,
+		handler: h,
+	}
+}
+
+// Consumer feeds records from a Source into a collection.  A Consumer must not be
+// modified while Run is in progress.
+type Consumer struct {
+	c       *sajari.Client
+	src     Source
+	handler Handler
+
+	// BatchSize is the maximum number of records added in each call to AddMulti.
+	// Defaults to DefaultBatchSize.
+	BatchSize int
+
+	// FlushInterval is the maximum time a message waits in a partial batch before the
+	// batch is added.  Defaults to DefaultFlushInterval.
+	FlushInterval time.Duration
+
+	// Retries is the number of times adds which fail with transient errors (unavailable,
+	// deadline exceeded, resource exhausted or aborted) are retried.  Defaults to
+	// DefaultRetries, set to a negative value to disable retries.
+	Retries int
+
+	// Backoff returns the delay before retry attempt (starting at 0).  Defaults to
+	// exponential backoff from 500ms, up to 30s.
+	Backoff func(attempt int) time.Duration
+
+	// Transforms are used when adding records (see sajari.Client.AddMulti).
+	Transforms []sajari.Transform
+
+	// DeadLetter is called with messages which could not be handled or added.  If
+	// nil then Run returns an error for the first such message, without acknowledging it.
+	DeadLetter DeadLetterFunc
+}
+
+// item is a message along with the record it was converted into, or the error returned
+// by the Handler.  An item with neither was skipped by the Handler.
+type item struct {
+	m   Message
+	rec sajari.Record
+	err error
+}
+
+// received is the result of a call to Source.Receive.
+type received struct {
+	m   Message
+	err error
+}
+
+// Run reads messages until the Source returns io.EOF (in which case Run returns nil once the
+// final batch has been added), the Source returns an error or ctx is done.  Messages in
+// a partial batch when ctx is done are not acknowledged, and will be redelivered by the
+// queue.
+func (c *Consumer) Run(ctx context.Context) error {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	ch := make(chan received)
+	go func() {
+		for {
+			m, err := c.src.Receive(ctx)
+			select {
+			case ch <- received{m, err}:
+			case <-ctx.Done():
+				return
+			}
+			if err != nil {
+				return
+			}
+		}
+	}()
+
+	batchSize := c.BatchSize
+	if batchSize <= 0 {
+		batchSize = DefaultBatchSize
+	}
+	interval := c.FlushInterval
+	if interval <= 0 {
+		interval = DefaultFlushInterval
+	}
+
+	var batch []item
+	var timeout <-chan time.Time
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+
+		case <-timeout:
+			if err := c.flush(ctx, batch); err != nil {
+				return err
+			}
+			batch, timeout = nil, nil
+
+		case r := <-ch:
+			if r.err != nil {
+				if err := c.flush(ctx, batch); err != nil {
+					return err
+				}
+				if r.err == io.EOF {
+					return nil
+				}
+				return r.err
+			}
+
+			rec, err := c.handler.Handle(ctx, r.m)
+			if err != nil && c.DeadLetter == nil {
+				if err := c.flush(ctx, batch); err != nil {
+					return err
+				}
+				return c.deadLetter(ctx, r.m, err)
+			}
+			if rec == nil && len(batch) == 0 {
+				// Nothing is waiting to be acknowledged ahead of the message.
+				if err := c.settle(ctx, item{r.m, nil, err}, nil); err != nil {
+					return err
+				}
+				continue
+			}
+
+			// Skipped and failed messages wait in the batch, so that they aren't
+			// acknowledged before the messages ahead of them.
+			batch = append(batch, item{r.m, rec, err})
+			if len(batch) == 1 {
+				timeout = time.After(interval)
+			}
+			if len(batch) >= batchSize {
+				if err := c.flush(ctx, batch); err != nil {
+					return err
+				}
+				batch, timeout = nil, nil
+			}
+		}
+	}
+}
+
+// flush adds the records in batch, retrying those which fail with transient errors, and
+// then acknowledges or dead-letters the messages in order.
+func (c *Consumer) flush(ctx context.Context, batch []item) error {
+	retries := c.Retries
+	if retries == 0 {
+		retries = DefaultRetries
+	}
+	backoff := c.Backoff
+	if backoff == nil {
+		backoff = retryutil.Backoff
+	}
+
+	// errs holds the error for each item whose record could not be added.
+	errs := make([]error, len(batch))
+	var pending []int
+	for i, it := range batch {
+		if it.rec != nil && it.err == nil {
+			pending = append(pending, i)
+		}
+	}
+
+	for attempt := 0; len(pending) > 0; attempt++ {
+		rs := make([]sajari.Record, 0, len(pending))
+		for _, i := range pending {
+			rs = append(rs, batch[i].rec)
+		}
+
+		_, err := c.c.AddMulti(ctx, rs, c.Transforms...)
+		if ctx.Err() != nil {
+			// Leave the messages unacknowledged so that they are redelivered.
+			return ctx.Err()
+		}
+
+		me, isMulti := err.(sajari.MultiError)
+		if isMulti && len(me) != len(pending) {
+			isMulti = false
+		}
+
+		var retry []int
+		unsent := false
+		for j, i := range pending {
+			ierr := err
+			if isMulti {
+				ierr = me[j]
+			}
+
+			switch {
+			case ierr == nil:
+			case ierr == sajari.ErrNotAdded:
+				retry = append(retry, i)
+				unsent = true
+			case attempt < retries && retryutil.IsTransient(ierr):
+				retry = append(retry, i)
+			default:
+				errs[i] = ierr
+			}
+		}
+
+		pending = retry
+		if len(pending) == 0 {
+			break
+		}
+		if unsent {
+			// Nothing was added because of invalid records in the batch, which
+			// have now been removed: resend the rest without using up a retry.
+			attempt--
+			continue
+		}
+
+		select {
+		case <-time.After(backoff(attempt)):
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	for i, it := range batch {
+		if err := c.settle(ctx, it, errs[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// settle acknowledges the message of it, or dead-letters it if the Handler or adding its
+// record (addErr) failed.
+func (c *Consumer) settle(ctx context.Context, it item, addErr error) error {
+	err := it.err
+	if err == nil {
+		err = addErr
+	}
+	if err != nil {
+		return c.deadLetter(ctx, it.m, err)
+	}
+	return it.m.Ack()
+}
+
+// deadLetter passes m to the DeadLetter callback and acknowledges it, or returns an error
+// if no callback is set.
+func (c *Consumer) deadLetter(ctx context.Context, m Message, err error) error {
+	if c.DeadLetter == nil {
+		return fmt.Errorf("ingest: could not add message: %v", err)
+	}
+	c.DeadLetter(ctx, m, err)
+	return m.Ack()
+}
@@ -0,0 +1,219 @@
+package ingest
+
+import (
+	"errors"
+	"io"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// acks records the order in which messages are acknowledged.
+type acks struct {
+	mu  sync.Mutex
+	ids []string
+}
+
+func (a *acks) add(id string) {
+	a.mu.Lock()
+	a.ids = append(a.ids, id)
+	a.mu.Unlock()
+}
+
+type message struct {
+	id   string
+	acks *acks
+}
+
+func (m *message) Data() []byte { return []byte(m.id) }
+
+func (m *message) Ack() error {
+	m.acks.add(m.id)
+	return nil
+}
+
+// source returns its messages in order, followed by io.EOF.
+type source struct {
+	ms []Message
+}
+
+func (s *source) Receive(ctx context.Context) (Message, error) {
+	if len(s.ms) == 0 {
+		return nil, io.EOF
+	}
+	m := s.ms[0]
+	s.ms = s.ms[1:]
+	return m, nil
+}
+
+// failFirstAdd is an interceptor which answers adds without making a request, failing
+// the first with a transient error.
+func failFirstAdd() grpc.UnaryClientInterceptor {
+	var mu sync.Mutex
+	failed := false
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if !failed {
+			failed = true
+			return grpc.Errorf(codes.Unavailable, "unavailable")
+		}
+		return nil
+	}
+}
+
+func TestConsumerAckOrder(t *testing.T) {
+	client, err := sajari.New("project", "collection",
+		sajari.WithEndpoint("localhost:0"),
+		sajari.WithGRPCDialOption(grpc.WithInsecure()),
+		sajari.WithUnaryInterceptor(failFirstAdd()),
+	)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer client.Close()
+
+	a := &acks{}
+	src := &source{}
+	for _, id := range []string{"skip-1", "add-2", "skip-3", "fail-4", "add-5", "skip-6"} {
+		src.ms = append(src.ms, &message{id, a})
+	}
+
+	h := HandlerFunc(func(ctx context.Context, m Message) (sajari.Record, error) {
+		id := string(m.Data())
+		switch id[:4] {
+		case "skip":
+			return nil, nil
+		case "fail":
+			return nil, errors.New("bad message")
+		}
+		return sajari.Record{"id": id}, nil
+	})
+
+	var deadLettered []string
+	c := New(client, src, h)
+	c.FlushInterval = time.Hour
+	c.Backoff = func(int) time.Duration { return 0 }
+	c.DeadLetter = func(ctx context.Context, m Message, err error) {
+		deadLettered = append(deadLettered, string(m.Data()))
+	}
+
+	if err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	want := []string{"skip-1", "add-2", "skip-3", "fail-4", "add-5", "skip-6"}
+	if !reflect.DeepEqual(a.ids, want) {
+		t.Errorf("acknowledged %v, expected %v", a.ids, want)
+	}
+	if want := []string{"fail-4"}; !reflect.DeepEqual(deadLettered, want) {
+		t.Errorf("dead-lettered %v, expected %v", deadLettered, want)
+	}
+}
+
+func TestConsumerNoDeadLetter(t *testing.T) {
+	client, err := sajari.New("project", "collection",
+		sajari.WithEndpoint("localhost:0"),
+		sajari.WithGRPCDialOption(grpc.WithInsecure()),
+		sajari.WithUnaryInterceptor(failFirstAdd()),
+	)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer client.Close()
+
+	a := &acks{}
+	src := &source{}
+	for _, id := range []string{"add-1", "fail-2", "add-3"} {
+		src.ms = append(src.ms, &message{id, a})
+	}
+
+	h := HandlerFunc(func(ctx context.Context, m Message) (sajari.Record, error) {
+		id := string(m.Data())
+		if id[:4] == "fail" {
+			return nil, errors.New("bad message")
+		}
+		return sajari.Record{"id": id}, nil
+	})
+
+	c := New(client, src, h)
+	c.FlushInterval = time.Hour
+	c.Backoff = func(int) time.Duration { return 0 }
+
+	if err := c.Run(context.Background()); err == nil {
+		t.Fatalf("Run() returned nil error, expected an error for fail-2")
+	}
+
+	// Messages received before the failure are added and acknowledged, the failed
+	// message and those after it are not.
+	if want := []string{"add-1"}; !reflect.DeepEqual(a.ids, want) {
+		t.Errorf("acknowledged %v, expected %v", a.ids, want)
+	}
+}
+
+func TestConsumerUnsupportedValue(t *testing.T) {
+	var mu sync.Mutex
+	calls := 0
+	client, err := sajari.New("project", "collection",
+		sajari.WithEndpoint("localhost:0"),
+		sajari.WithGRPCDialOption(grpc.WithInsecure()),
+		sajari.WithUnaryInterceptor(func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+			mu.Lock()
+			calls++
+			mu.Unlock()
+			return nil
+		}),
+	)
+	if err != nil {
+		t.Fatalf("New() error: %v", err)
+	}
+	defer client.Close()
+
+	a := &acks{}
+	src := &source{}
+	for _, id := range []string{"add-1", "bad-2", "add-3"} {
+		src.ms = append(src.ms, &message{id, a})
+	}
+
+	h := HandlerFunc(func(ctx context.Context, m Message) (sajari.Record, error) {
+		id := string(m.Data())
+		if id[:3] == "bad" {
+			return sajari.Record{"id": id, "value": struct{}{}}, nil
+		}
+		return sajari.Record{"id": id}, nil
+	})
+
+	var deadLettered []string
+	c := New(client, src, h)
+	c.FlushInterval = time.Hour
+	c.Retries = -1
+	c.DeadLetter = func(ctx context.Context, m Message, err error) {
+		if _, ok := err.(sajari.ValueErrors); !ok {
+			t.Errorf("dead-lettered %s with %T (%v), expected sajari.ValueErrors", m.Data(), err, err)
+		}
+		deadLettered = append(deadLettered, string(m.Data()))
+	}
+
+	if err := c.Run(context.Background()); err != nil {
+		t.Fatalf("Run() error: %v", err)
+	}
+
+	// The valid records are resent without the invalid one, even with retries disabled.
+	if want := []string{"add-1", "bad-2", "add-3"}; !reflect.DeepEqual(a.ids, want) {
+		t.Errorf("acknowledged %v, expected %v", a.ids, want)
+	}
+	if want := []string{"bad-2"}; !reflect.DeepEqual(deadLettered, want) {
+		t.Errorf("dead-lettered %v, expected %v", deadLettered, want)
+	}
+	if calls != 1 {
+		t.Errorf("made %d add requests, expected 1", calls)
+	}
+}
@@ -0,0 +1,113 @@
+// Package benchutil runs repeated queries and summarises their latency and throughput, for
+// the -count and -concurrency flags of the commands in cmd/.
+package benchutil
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// Stats collects statistics from repeated queries.
+type Stats struct {
+	mu sync.Mutex
+
+	latencies    []time.Duration
+	errors       int
+	totalResults int
+	totalReads   int
+	totalTime    time.Duration
+}
+
+// Add records the outcome of a query which took latency to complete.
+func (s *Stats) Add(resp *sajari.Results, latency time.Duration, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.latencies = append(s.latencies, latency)
+	if err != nil {
+		s.errors++
+		return
+	}
+	s.totalResults = resp.TotalResults
+	s.totalTime += resp.Time
+	s.totalReads += resp.Reads
+}
+
+// Print writes a summary of the statistics to w.  Latency percentiles and
+// throughput are only written if more than one query was run.
+func (s *Stats) Print(w io.Writer, wall time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	fmt.Fprintln(w, "Total Results", s.totalResults)
+	fmt.Fprintln(w, "Reads", s.totalReads)
+	fmt.Fprintln(w, "Time", s.totalTime)
+
+	if s.totalReads > 0 {
+		fmt.Fprintln(w, "Time per Read:", time.Duration(int64(s.totalTime)/int64(s.totalReads)))
+	}
+
+	n := len(s.latencies)
+	if n < 2 {
+		return
+	}
+
+	sort.Sort(durations(s.latencies))
+	fmt.Fprintln(w, "Queries", n)
+	fmt.Fprintln(w, "Errors", s.errors)
+	fmt.Fprintln(w, "Wall Time", wall)
+	fmt.Fprintf(w, "QPS %.2f\n", float64(n)/wall.Seconds())
+	fmt.Fprintln(w, "Latency p50", Percentile(s.latencies, 50))
+	fmt.Fprintln(w, "Latency p95", Percentile(s.latencies, 95))
+	fmt.Fprintln(w, "Latency p99", Percentile(s.latencies, 99))
+	fmt.Fprintln(w, "Latency max", s.latencies[n-1])
+}
+
+// Percentile returns the pth percentile of the sorted durations ds, using the
+// nearest-rank method.
+func Percentile(ds []time.Duration, p int) time.Duration {
+	i := (len(ds)*p + 99) / 100
+	if i < 1 {
+		i = 1
+	}
+	return ds[i-1]
+}
+
+type durations []time.Duration
+
+func (d durations) Len() int           { return len(d) }
+func (d durations) Less(i, j int) bool { return d[i] < d[j] }
+func (d durations) Swap(i, j int)      { d[i], d[j] = d[j], d[i] }
+
+// Run runs n queries using c concurrent workers, calling f to run each
+// query and recording the outcome in s.  At least one worker is used.
+func Run(n, c int, s *Stats, f func() (*sajari.Results, error)) {
+	if c < 1 {
+		c = 1
+	}
+
+	ch := make(chan struct{})
+	wg := sync.WaitGroup{}
+	for i := 0; i < c; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range ch {
+				start := time.Now()
+				resp, err := f()
+				s.Add(resp, time.Since(start), err)
+			}
+		}()
+	}
+
+	for i := 0; i < n; i++ {
+		ch <- struct{}{}
+	}
+	close(ch)
+	wg.Wait()
+}
@@ -0,0 +1,294 @@
+// Package cliutil provides the connection flags and configuration shared by the
+// commands in cmd/.
+//
+// Connection settings are read from a profile in the config file
+// (~/.sajari/config, or $SAJARI_CONFIG), then overridden by environment
+// variables, then by command line flags.  The config file contains named
+// profiles:
+//
+//	[default]
+//	project = my-project
+//	collection = my-collection
+//	creds = key-id,key-secret
+//
+//	[staging]
+//	endpoint = staging.example.com:443
+//	project = my-project
+//	collection = my-collection-staging
+//
+// Credentials can also be given as separate key-id and key-secret settings.
+// To keep the secret out of the config file, key-secret-command runs a command
+// which prints it, for instance to read it from the OS keychain:
+//
+//	key-id = key-id
+//	key-secret-command = security find-generic-password -s sajari -w
+//
+// or with libsecret on Linux:
+//
+//	key-secret-command = secret-tool lookup service sajari
+//
+// The profile is chosen with -profile or $SAJARI_PROFILE, and defaults to
+// "default".  Settings are overridden by $SAJARI_ENDPOINT, $SAJARI_PROJECT,
+// $SAJARI_COLLECTION, $SAJARI_CREDS, $SAJARI_KEY_ID and $SAJARI_KEY_SECRET,
+// so credentials need not be passed with -creds where they would be saved
+// in shell history.
+package cliutil
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// DefaultProfile is the profile used when none is specified.
+const DefaultProfile = "default"
+
+// Flags are the connection flags shared by all commands.
+type Flags struct {
+	Endpoint   *string
+	Project    *string
+	Collection *string
+	Creds      *string
+	Profile    *string
+}
+
+// AddFlags registers the connection flags with fs.
+func AddFlags(fs *flag.FlagSet) *Flags {
+	return &Flags{
+		Endpoint:   fs.String("endpoint", "", "endpoint `address`, uses default if not set"),
+		Project:    fs.String("project", "", "project `name` to use"),
+		Collection: fs.String("collection", "", "collection `name` to use"),
+		Creds:      fs.String("creds", "", "calling credentials in the form `key-id,key-secret`, see also $SAJARI_KEY_ID and $SAJARI_KEY_SECRET"),
+		Profile:    fs.String("profile", "", "`name` of profile in the config file to load settings from (default \"default\")"),
+	}
+}
+
+// Config is the configuration used to connect to a collection.
+type Config struct {
+	Endpoint   string
+	Project    string
+	Collection string
+	KeyID      string
+	KeySecret  string
+}
+
+// Config returns the configuration from the config file profile, environment
+// variables and flags (in increasing order of precedence).
+func (f *Flags) Config() (*Config, error) {
+	profile := *f.Profile
+	if profile == "" {
+		profile = os.Getenv("SAJARI_PROFILE")
+	}
+
+	c := &Config{}
+	if err := c.loadProfile(profile); err != nil {
+		return nil, err
+	}
+
+	if err := c.set([]setting{
+		{"endpoint", os.Getenv("SAJARI_ENDPOINT")},
+		{"project", os.Getenv("SAJARI_PROJECT")},
+		{"collection", os.Getenv("SAJARI_COLLECTION")},
+		{"creds", os.Getenv("SAJARI_CREDS")},
+		{"key-id", os.Getenv("SAJARI_KEY_ID")},
+		{"key-secret", os.Getenv("SAJARI_KEY_SECRET")},
+	}); err != nil {
+		return nil, fmt.Errorf("environment: %v", err)
+	}
+
+	if err := c.set([]setting{
+		{"endpoint", *f.Endpoint},
+		{"project", *f.Project},
+		{"collection", *f.Collection},
+		{"creds", *f.Creds},
+	}); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+// NewClient creates a client using the configuration from Config.  Any
+// additional options are applied after those from the configuration.
+func (f *Flags) NewClient(opts ...sajari.Opt) (*sajari.Client, error) {
+	c, err := f.Config()
+	if err != nil {
+		return nil, err
+	}
+
+	if c.Project == "" {
+		return nil, fmt.Errorf("project not set, use -project, $SAJARI_PROJECT or a config file profile")
+	}
+	if c.Collection == "" {
+		return nil, fmt.Errorf("collection not set, use -collection, $SAJARI_COLLECTION or a config file profile")
+	}
+	return sajari.New(c.Project, c.Collection, append(c.Opts(), opts...)...)
+}
+
+// Opts returns the client options for the endpoint and credentials.
+func (c *Config) Opts() []sajari.Opt {
+	var opts []sajari.Opt
+	if c.Endpoint != "" {
+		opts = append(opts, sajari.WithEndpoint(c.Endpoint))
+	}
+	if c.KeyID != "" || c.KeySecret != "" {
+		opts = append(opts, sajari.WithCredentials(sajari.KeyCredentials(c.KeyID, c.KeySecret)))
+	}
+	return opts
+}
+
+// setting is a named configuration value.
+type setting struct {
+	key, value string
+}
+
+// set updates c with the non-empty values in ss, in order.
+func (c *Config) set(ss []setting) error {
+	for _, s := range ss {
+		k, v := s.key, s.value
+		if v == "" {
+			continue
+		}
+
+		switch k {
+		case "endpoint":
+			c.Endpoint = v
+
+		case "project":
+			c.Project = v
+
+		case "collection":
+			c.Collection = v
+
+		case "creds":
+			credsSplit := strings.Split(v, ",")
+			if len(credsSplit) != 2 {
+				return fmt.Errorf("creds: expected 'id,secret', got '%v'", v)
+			}
+			c.KeyID, c.KeySecret = credsSplit[0], credsSplit[1]
+
+		case "key-id":
+			c.KeyID = v
+
+		case "key-secret":
+			c.KeySecret = v
+
+		case "key-secret-command":
+			secret, err := runSecretCommand(v)
+			if err != nil {
+				return fmt.Errorf("key-secret-command: %v", err)
+			}
+			c.KeySecret = secret
+
+		default:
+			return fmt.Errorf("unknown setting %q", k)
+		}
+	}
+	return nil
+}
+
+// ConfigPath returns the path of the config file.
+func ConfigPath() string {
+	if p := os.Getenv("SAJARI_CONFIG"); p != "" {
+		return p
+	}
+
+	home := os.Getenv("HOME")
+	if home == "" {
+		home = os.Getenv("USERPROFILE")
+	}
+	return filepath.Join(home, ".sajari", "config")
+}
+
+// loadProfile sets c from the named profile in the config file.  A missing
+// config file is only an error if a profile was explicitly requested.
+func (c *Config) loadProfile(name string) error {
+	path := ConfigPath()
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) && name == "" {
+			return nil
+		}
+		return fmt.Errorf("error reading config file: %v", err)
+	}
+	defer f.Close()
+
+	profiles, err := parseConfig(f)
+	if err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+
+	if name == "" {
+		name = DefaultProfile
+	}
+
+	p, ok := profiles[name]
+	if !ok {
+		if name == DefaultProfile {
+			return nil
+		}
+		return fmt.Errorf("%v: profile %q not found", path, name)
+	}
+
+	if err := c.set(p); err != nil {
+		return fmt.Errorf("%v: profile %q: %v", path, name, err)
+	}
+	return nil
+}
+
+// parseConfig parses the profiles in a config file.  Lines are either
+// [profile] headers, key = value settings, blank or comments beginning
+// with # or ;.
+func parseConfig(r io.Reader) (map[string][]setting, error) {
+	profiles := make(map[string][]setting)
+	current := ""
+
+	s := bufio.NewScanner(r)
+	for n := 1; s.Scan(); n++ {
+		line := strings.TrimSpace(s.Text())
+		switch {
+		case line == "", strings.HasPrefix(line, "#"), strings.HasPrefix(line, ";"):
+			continue
+
+		case strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]"):
+			current = strings.TrimSpace(line[1 : len(line)-1])
+			if _, ok := profiles[current]; !ok {
+				profiles[current] = nil
+			}
+			continue
+		}
+
+		kv := strings.SplitN(line, "=", 2)
+		if len(kv) != 2 {
+			return nil, fmt.Errorf("line %d: expected key = value, got %q", n, line)
+		}
+		if current == "" {
+			return nil, fmt.Errorf("line %d: setting outside of a [profile]", n)
+		}
+		profiles[current] = append(profiles[current], setting{strings.TrimSpace(kv[0]), strings.TrimSpace(kv[1])})
+	}
+	return profiles, s.Err()
+}
+
+// runSecretCommand runs the command line cmd and returns its output, with
+// surrounding whitespace removed.  Arguments are separated by spaces and are
+// not otherwise interpreted.
+func runSecretCommand(cmd string) (string, error) {
+	args := strings.Fields(cmd)
+	out, err := exec.Command(args[0], args[1:]...).Output()
+	if err != nil {
+		return "", err
+	}
+
+	secret := strings.TrimSpace(string(out))
+	if secret == "" {
+		return "", fmt.Errorf("%v: no output", args[0])
+	}
+	return secret, nil
+}
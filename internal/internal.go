@@ -11,10 +11,17 @@ const (
 	collectionKey = "collection"
 )
 
+// NewContext returns a context with outgoing metadata identifying the project and
+// collection.  Any outgoing metadata already attached to ctx is preserved, with the
+// exception of the project and collection keys which are replaced.
 func NewContext(ctx context.Context, project, collection string) context.Context {
-	m := map[string]string{
-		projectKey:    project,
-		collectionKey: collection,
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = make(metadata.MD, 2)
+	} else {
+		md = md.Copy()
 	}
-	return metadata.NewOutgoingContext(ctx, metadata.New(m))
+	md[projectKey] = []string{project}
+	md[collectionKey] = []string{collection}
+	return metadata.NewOutgoingContext(ctx, md)
 }
@@ -0,0 +1,33 @@
+// Package retryutil provides the transient error check and backoff shared by the retry
+// loops in ingest and cmd/csv-import.
+package retryutil
+
+import (
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// IsTransient returns true if err is likely to succeed if retried.
+func IsTransient(err error) bool {
+	switch grpc.Code(err) {
+	case codes.Unavailable, codes.DeadlineExceeded, codes.ResourceExhausted, codes.Aborted:
+		return true
+	}
+	return false
+}
+
+// Backoff returns the delay before retry attempt (starting at 0): exponential backoff
+// from 500ms, up to 30s.
+func Backoff(attempt int) time.Duration {
+	// 500ms << 6 is beyond the maximum, capping the attempt avoids overflow.
+	if attempt > 6 {
+		attempt = 6
+	}
+	d := 500 * time.Millisecond << uint(attempt)
+	if d > 30*time.Second {
+		d = 30 * time.Second
+	}
+	return d
+}
@@ -0,0 +1,27 @@
+package retryutil
+
+import (
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		attempt int
+		want    time.Duration
+	}{
+		{0, 500 * time.Millisecond},
+		{1, time.Second},
+		{5, 16 * time.Second},
+		{6, 30 * time.Second},
+		{35, 30 * time.Second},
+		{64, 30 * time.Second},
+		{1000, 30 * time.Second},
+	}
+
+	for _, tt := range tests {
+		if got := Backoff(tt.attempt); got != tt.want {
+			t.Errorf("Backoff(%d) = %v, expected %v", tt.attempt, got, tt.want)
+		}
+	}
+}
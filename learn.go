@@ -20,24 +20,76 @@ func (c *Client) LearnMulti(ctx context.Context, ks []*Key, r Request, counts []
 		return err
 	}
 
-	pbks, err := keys(ks).proto()
-	if err != nil {
-		return err
-	}
-
-	keysScores := make([]*recpb.KeyScores, 0, len(pbks))
-	for i, k := range pbks {
-		keysScores = append(keysScores, &recpb.KeyScores{
+	kss := make([]KeyScores, 0, len(ks))
+	for i, k := range ks {
+		kss = append(kss, KeyScores{
 			Key: k,
-			Scores: []*recpb.KeyScores_Score{
-				&recpb.KeyScores_Score{
+			Scores: []Score{
+				{
 					Terms: ars[i],
-					Count: int32(counts[i]),
+					Count: counts[i],
 					Score: scores[i],
 				},
 			},
 		})
 	}
+	return c.LearnScores(ctx, kss)
+}
+
+// KeyScores is a list of score updates to apply to the record identified by Key.
+type KeyScores struct {
+	// Key identifies the record.
+	Key *Key
+
+	// Scores to apply to the record.
+	Scores []Score
+}
+
+// Score is a score update which is applied to instances of terms in a record.
+type Score struct {
+	// Terms are the terms in the record which the update applies to.
+	Terms []string
+
+	// Count is the number of interactions the update represents.
+	Count int
+
+	// Score is the score applied for the interactions.
+	Score float32
+}
+
+func (ks KeyScores) proto() (*recpb.KeyScores, error) {
+	k, err := ks.Key.proto()
+	if err != nil {
+		return nil, err
+	}
+
+	scores := make([]*recpb.KeyScores_Score, 0, len(ks.Scores))
+	for _, s := range ks.Scores {
+		scores = append(scores, &recpb.KeyScores_Score{
+			Terms: s.Terms,
+			Count: int32(s.Count),
+			Score: s.Score,
+		})
+	}
+	return &recpb.KeyScores{
+		Key:    k,
+		Scores: scores,
+	}, nil
+}
+
+// LearnScores applies score updates to records.  Unlike LearnMulti, the terms
+// each update applies to are given explicitly (see Query.Analyse) and each record can
+// receive any number of updates in a single request.  If any of the updates fail then
+// a MultiError will be returned with errors set in the respective indexes.
+func (c *Client) LearnScores(ctx context.Context, kss []KeyScores) error {
+	keysScores := make([]*recpb.KeyScores, 0, len(kss))
+	for _, ks := range kss {
+		pbks, err := ks.proto()
+		if err != nil {
+			return err
+		}
+		keysScores = append(keysScores, pbks)
+	}
 
 	resp, err := recpb.NewScoreClient(c.ClientConn).Increment(c.newContext(ctx), &recpb.IncrementRequest{
 		KeysScores: keysScores,
@@ -0,0 +1,108 @@
+package sajari
+
+// Clone returns a deep copy of the Request: slices and maps are copied, so that the clone can
+// be modified without affecting r.  Filters, sorts, aggregates and boosts are immutable once
+// created, so are shared between r and the clone.
+func (r Request) Clone() Request {
+	out := r
+
+	out.Tracking.Data = copyStringMap(r.Tracking.Data)
+
+	out.IndexQuery.Body = append([]Body(nil), r.IndexQuery.Body...)
+	out.IndexQuery.Terms = append([]Term(nil), r.IndexQuery.Terms...)
+	out.IndexQuery.FieldBoosts = append([]FieldBoost(nil), r.IndexQuery.FieldBoosts...)
+	out.IndexQuery.InstanceBoosts = append([]InstanceBoost(nil), r.IndexQuery.InstanceBoosts...)
+	out.FeatureQuery.FieldBoosts = append([]FeatureFieldBoost(nil), r.FeatureQuery.FieldBoosts...)
+
+	out.Sort = append([]Sort(nil), r.Sort...)
+	out.Fields = append([]string(nil), r.Fields...)
+	out.Transforms = append([]Transform(nil), r.Transforms...)
+
+	if r.Aggregates != nil {
+		out.Aggregates = make(map[string]Aggregate, len(r.Aggregates))
+		for k, v := range r.Aggregates {
+			out.Aggregates[k] = v
+		}
+	}
+	return out
+}
+
+// Merge returns a new Request which combines base with override.  Neither base nor override
+// are modified, and the result does not share slices or maps with them (see Request.Clone).
+//
+// Fields are merged as follows:
+//   - Filter: if both are set then records must match both filters (see AllFilters).
+//   - IndexQuery.Text, Offset and Limit: the override value is used if it is non-zero.
+//   - IndexQuery.Body, IndexQuery.Terms, field boosts, instance boosts and Transforms: the
+//     override values are appended to the base values.
+//   - Sort and Fields: the override value is used if it is non-empty.
+//   - Aggregates: the union of both, with override aggregates replacing base aggregates
+//     with the same name.
+//   - Tracking: the override value is used if its Type is set.
+//   - Profile: the override value is used if it is non-empty.
+//   - SkipResults and IncludeArchived: set if set in either.
+func Merge(base, override Request) Request {
+	out := base.Clone()
+	o := override.Clone()
+
+	switch {
+	case out.Filter == nil:
+		out.Filter = o.Filter
+	case o.Filter != nil:
+		out.Filter = AllFilters(out.Filter, o.Filter)
+	}
+
+	if o.IndexQuery.Text != "" {
+		out.IndexQuery.Text = o.IndexQuery.Text
+	}
+	if o.Offset != 0 {
+		out.Offset = o.Offset
+	}
+	if o.Limit != 0 {
+		out.Limit = o.Limit
+	}
+
+	out.IndexQuery.Body = append(out.IndexQuery.Body, o.IndexQuery.Body...)
+	out.IndexQuery.Terms = append(out.IndexQuery.Terms, o.IndexQuery.Terms...)
+	out.IndexQuery.FieldBoosts = append(out.IndexQuery.FieldBoosts, o.IndexQuery.FieldBoosts...)
+	out.IndexQuery.InstanceBoosts = append(out.IndexQuery.InstanceBoosts, o.IndexQuery.InstanceBoosts...)
+	out.FeatureQuery.FieldBoosts = append(out.FeatureQuery.FieldBoosts, o.FeatureQuery.FieldBoosts...)
+	out.Transforms = append(out.Transforms, o.Transforms...)
+
+	if len(o.Sort) > 0 {
+		out.Sort = o.Sort
+	}
+	if len(o.Fields) > 0 {
+		out.Fields = o.Fields
+	}
+
+	if len(o.Aggregates) > 0 {
+		if out.Aggregates == nil {
+			out.Aggregates = make(map[string]Aggregate, len(o.Aggregates))
+		}
+		for k, v := range o.Aggregates {
+			out.Aggregates[k] = v
+		}
+	}
+
+	if o.Tracking.Type != TrackingNone {
+		out.Tracking = o.Tracking
+	}
+	if o.Profile != "" {
+		out.Profile = o.Profile
+	}
+	out.SkipResults = out.SkipResults || o.SkipResults
+	out.IncludeArchived = out.IncludeArchived || o.IncludeArchived
+	return out
+}
+
+func copyStringMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+	out := make(map[string]string, len(m))
+	for k, v := range m {
+		out[k] = v
+	}
+	return out
+}
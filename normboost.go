@@ -0,0 +1,86 @@
+package sajari
+
+import (
+	"fmt"
+	"math"
+
+	pb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+)
+
+// The boosts in this file are built on IntervalFieldBoost, and define common
+// normalisation curves for numeric fields.
+
+// PercentageFieldBoost is an interval-based boost which scales linearly with the value
+// of field as a proportion of max: a value of 0 has boost 0, and a value of max has boost 1.
+// The max must be positive.
+func PercentageFieldBoost(field string, max float64) FieldBoost {
+	if !(max > 0) || math.IsInf(max, 0) {
+		return invalidFieldBoost{fmt.Errorf("percentage boost on %q: max must be positive, got %v", field, max)}
+	}
+	return IntervalFieldBoost(field,
+		IntervalPoint{Point: 0, Value: 0},
+		IntervalPoint{Point: max, Value: 1},
+	)
+}
+
+// zScorePoints are the standard scores used to approximate the normal distribution
+// in ZScoreFieldBoost.
+var zScorePoints = []float64{-3, -2, -1.5, -1, -0.5, 0, 0.5, 1, 1.5, 2, 3}
+
+// ZScoreFieldBoost is an interval-based boost which approximates the cumulative normal
+// distribution of the value of field, given the mean and standard deviation of the
+// field values in the collection.  A value at the mean has boost 0.5, and values more
+// than three standard deviations either side of the mean have boosts close to 0 and 1
+// respectively.  The stddev must be positive.
+func ZScoreFieldBoost(field string, mean, stddev float64) FieldBoost {
+	if !(stddev > 0) || math.IsInf(stddev, 0) {
+		return invalidFieldBoost{fmt.Errorf("z-score boost on %q: stddev must be positive, got %v", field, stddev)}
+	}
+
+	points := make([]IntervalPoint, 0, len(zScorePoints))
+	for _, z := range zScorePoints {
+		points = append(points, IntervalPoint{
+			Point: mean + z*stddev,
+			Value: 0.5 * math.Erfc(-z/math.Sqrt2),
+		})
+	}
+	return IntervalFieldBoost(field, points...)
+}
+
+// logScaleSteps is the number of intervals used to approximate the curve in
+// LogScaleFieldBoost.
+const logScaleSteps = 10
+
+// LogScaleFieldBoost is an interval-based boost which scales logarithmically with the
+// value of field between min and max: a value of min has boost 0, a value of max has
+// boost 1, and in between the boost increases by the same amount each time the value
+// grows by the same factor (e.g. from 10 to 100 and from 100 to 1000).  This is useful for
+// fields such as counts or prices where values span several orders of magnitude.  The min
+// must be positive and less than max.
+func LogScaleFieldBoost(field string, min, max float64) FieldBoost {
+	if !(min > 0) || !(max > min) || math.IsInf(max, 0) {
+		return invalidFieldBoost{fmt.Errorf("log-scale boost on %q: require 0 < min < max, got min %v, max %v", field, min, max)}
+	}
+
+	r := math.Log(max / min)
+	points := make([]IntervalPoint, 0, logScaleSteps+1)
+	for i := 0; i < logScaleSteps; i++ {
+		v := float64(i) / logScaleSteps
+		points = append(points, IntervalPoint{
+			Point: min * math.Exp(v*r),
+			Value: v,
+		})
+	}
+	points = append(points, IntervalPoint{Point: max, Value: 1})
+	return IntervalFieldBoost(field, points...)
+}
+
+// invalidFieldBoost is returned by boost constructors given invalid arguments, so that
+// the error is reported when the request is run.
+type invalidFieldBoost struct {
+	err error
+}
+
+func (b invalidFieldBoost) proto() (*pb.FieldBoost, error) {
+	return nil, b.err
+}
@@ -1,6 +1,10 @@
 package sajari
 
-import "google.golang.org/grpc"
+import (
+	"time"
+
+	"google.golang.org/grpc"
+)
 
 // Opt is a type which defines Client options.
 type Opt func(c *Client)
@@ -12,6 +16,14 @@ func WithEndpoint(endpoint string) Opt {
 	}
 }
 
+// WithUserAgentSuffix appends suffix (e.g. "checkout-service/1.4") to the user agent sent
+// with each request, so that requests can be attributed to the calling application.
+func WithUserAgentSuffix(suffix string) Opt {
+	return func(c *Client) {
+		c.userAgentSuffix = suffix
+	}
+}
+
 // WithCredentials sets the client credentials used in each request.
 func WithCredentials(c Credentials) Opt {
 	return WithGRPCDialOption(grpc.WithPerRPCCredentials(creds{c}))
@@ -24,3 +36,48 @@ func WithGRPCDialOption(opt grpc.DialOption) Opt {
 		c.dialOpts = append(c.dialOpts, opt)
 	}
 }
+
+// WithWarmUp configures New to establish the connection and make an authenticated request
+// before returning, so that the first request made by the Client doesn't pay the cost of
+// dialing, TLS negotiation and authentication.  If warm-up does not complete within timeout
+// then New returns an error.  A zero timeout means no limit.
+//
+// The warm-up request is an empty search, which only requires query permission.  If
+// prefetchSchema is set then the collection schema is fetched instead, which will prime the
+// schema cache if it has been enabled (see WithSchemaCache) but requires the key to have
+// schema permission.
+func WithWarmUp(timeout time.Duration, prefetchSchema bool) Opt {
+	return func(c *Client) {
+		c.warmUp = true
+		c.warmUpSchema = prefetchSchema
+		c.warmUpTimeout = timeout
+	}
+}
+
+// WithBorrowedValues configures the client to return repeated field values ([]string) which
+// share memory with the underlying response messages, rather than copies.  This reduces
+// allocations in read-heavy applications, but callers must not modify the returned slices.
+//
+// By default repeated values are copied, and are safe to modify.
+func WithBorrowedValues() Opt {
+	return func(c *Client) {
+		c.borrowValues = true
+	}
+}
+
+// WithUnaryInterceptor configures the client to run i on each RPC.  Interceptors are run
+// in the order in which they are added.
+func WithUnaryInterceptor(i grpc.UnaryClientInterceptor) Opt {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, i)
+	}
+}
+
+// WithDefaultAddTransforms sets the transforms used by Add and AddMulti when none are
+// specified.  If not set then DefaultAddTransforms is used.  With no arguments, records
+// are added without any transforms by default.
+func WithDefaultAddTransforms(ts ...Transform) Opt {
+	return func(c *Client) {
+		c.defaultAddTransforms = append([]Transform{}, ts...)
+	}
+}
@@ -0,0 +1,257 @@
+package sajari
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseFilter parses a filter expression.  Expressions are made up of field
+// comparisons of the form field op value, where op is one of the operators
+// supported by FieldFilter, combined with AND, OR, NOT and parentheses.
+// AND binds more tightly than OR.  Values containing whitespace, parentheses,
+// quotes or any of the operator characters <>=!~^$ must be double quoted (using
+// Go string syntax).
+//
+// Filter which matches articles published since 1500000000, or any record
+// whose title contains "sajari":
+//
+//	ParseFilter(`(type = article AND published >= 1500000000) OR title ~ "sajari"`)
+//
+// Filter which matches records which don't have an https url:
+//
+//	ParseFilter(`NOT url ^ "https://"`)
+func ParseFilter(s string) (Filter, error) {
+	p := &filterParser{s: s}
+	p.next()
+
+	f, err := p.parseOr()
+	if err != nil {
+		return nil, err
+	}
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokEOF {
+		return nil, p.errorf("unexpected %v", p.tok)
+	}
+	return f, nil
+}
+
+type filterTokenKind int
+
+const (
+	tokEOF filterTokenKind = iota
+	tokWord
+	tokString
+	tokOp
+	tokLParen
+	tokRParen
+)
+
+type filterToken struct {
+	kind filterTokenKind
+	text string
+	pos  int
+}
+
+func (t filterToken) String() string {
+	switch t.kind {
+	case tokEOF:
+		return "end of filter"
+	case tokString:
+		return strconv.Quote(t.text)
+	}
+	return fmt.Sprintf("%q", t.text)
+}
+
+// filterOpChars are the characters which make up field filter operators.
+const filterOpChars = "<>=!~^$"
+
+// isFilterSpace returns true if c is ASCII whitespace.  Input is scanned a byte at a
+// time, so only ASCII whitespace separates tokens: bytes of multi-byte UTF-8
+// characters are always part of a word.
+func isFilterSpace(c byte) bool {
+	switch c {
+	case ' ', '\t', '\n', '\v', '\f', '\r':
+		return true
+	}
+	return false
+}
+
+type filterParser struct {
+	s   string
+	pos int
+	tok filterToken
+	err error
+}
+
+func (p *filterParser) errorf(format string, args ...interface{}) error {
+	return fmt.Errorf("filter: at position %d: %v", p.tok.pos+1, fmt.Sprintf(format, args...))
+}
+
+// next reads the next token into p.tok.  Scanning errors are stored in p.err.
+func (p *filterParser) next() {
+	for p.pos < len(p.s) && isFilterSpace(p.s[p.pos]) {
+		p.pos++
+	}
+
+	start := p.pos
+	if p.pos >= len(p.s) {
+		p.tok = filterToken{kind: tokEOF, pos: start}
+		return
+	}
+
+	switch c := p.s[p.pos]; {
+	case c == '(':
+		p.pos++
+		p.tok = filterToken{kind: tokLParen, text: "(", pos: start}
+
+	case c == ')':
+		p.pos++
+		p.tok = filterToken{kind: tokRParen, text: ")", pos: start}
+
+	case c == '"':
+		end := p.pos + 1
+		for end < len(p.s) && p.s[end] != '"' {
+			if p.s[end] == '\\' {
+				end++
+			}
+			end++
+		}
+		if end >= len(p.s) {
+			p.tok = filterToken{kind: tokEOF, pos: start}
+			p.err = fmt.Errorf("filter: at position %d: unterminated string", start+1)
+			return
+		}
+		text, err := strconv.Unquote(p.s[p.pos : end+1])
+		if err != nil {
+			p.err = fmt.Errorf("filter: at position %d: invalid string: %v", start+1, err)
+		}
+		p.pos = end + 1
+		p.tok = filterToken{kind: tokString, text: text, pos: start}
+
+	case strings.IndexByte(filterOpChars, c) >= 0:
+		for p.pos < len(p.s) && strings.IndexByte(filterOpChars, p.s[p.pos]) >= 0 {
+			p.pos++
+		}
+		p.tok = filterToken{kind: tokOp, text: p.s[start:p.pos], pos: start}
+
+	default:
+		for p.pos < len(p.s) {
+			c := p.s[p.pos]
+			if isFilterSpace(c) || c == '(' || c == ')' || c == '"' || strings.IndexByte(filterOpChars, c) >= 0 {
+				break
+			}
+			p.pos++
+		}
+		p.tok = filterToken{kind: tokWord, text: p.s[start:p.pos], pos: start}
+	}
+}
+
+// keyword returns true if the current token is the keyword kw (case insensitive).
+func (p *filterParser) keyword(kw string) bool {
+	return p.tok.kind == tokWord && strings.EqualFold(p.tok.text, kw)
+}
+
+func (p *filterParser) parseOr() (Filter, error) {
+	f, err := p.parseAnd()
+	if err != nil {
+		return nil, err
+	}
+
+	fs := []Filter{f}
+	for p.keyword("OR") {
+		p.next()
+		f, err := p.parseAnd()
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+
+	if len(fs) == 1 {
+		return fs[0], nil
+	}
+	return AnyFilter(fs...), nil
+}
+
+func (p *filterParser) parseAnd() (Filter, error) {
+	f, err := p.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	fs := []Filter{f}
+	for p.keyword("AND") {
+		p.next()
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		fs = append(fs, f)
+	}
+
+	if len(fs) == 1 {
+		return fs[0], nil
+	}
+	return AllFilters(fs...), nil
+}
+
+func (p *filterParser) parseUnary() (Filter, error) {
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	switch {
+	case p.keyword("NOT"):
+		p.next()
+		f, err := p.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+		return NoneOfFilters(f), nil
+
+	case p.tok.kind == tokLParen:
+		p.next()
+		f, err := p.parseOr()
+		if err != nil {
+			return nil, err
+		}
+		if p.tok.kind != tokRParen {
+			return nil, p.errorf("expected ), got %v", p.tok)
+		}
+		p.next()
+		return f, nil
+	}
+	return p.parseComparison()
+}
+
+func (p *filterParser) parseComparison() (Filter, error) {
+	if p.tok.kind != tokWord || p.keyword("AND") || p.keyword("OR") {
+		return nil, p.errorf("expected field name, got %v", p.tok)
+	}
+	field := p.tok.text
+	p.next()
+
+	if p.tok.kind != tokOp {
+		return nil, p.errorf("expected operator after %q, got %v", field, p.tok)
+	}
+	op := p.tok.text
+	switch op {
+	case "=", "!=", ">", ">=", "<", "<=", "~", "!~", "^", "$":
+	default:
+		return nil, p.errorf("invalid operator %q", op)
+	}
+	p.next()
+
+	if p.err != nil {
+		return nil, p.err
+	}
+	if p.tok.kind != tokWord && p.tok.kind != tokString {
+		return nil, p.errorf("expected value after %q, got %v", field+" "+op, p.tok)
+	}
+	value := p.tok.text
+	p.next()
+	return FieldFilter(field+" "+op, value), nil
+}
@@ -0,0 +1,93 @@
+package sajari
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseFilter(t *testing.T) {
+	a := FieldFilter("a =", "1")
+	b := FieldFilter("b =", "2")
+	c := FieldFilter("c =", "3")
+
+	tests := []struct {
+		s    string
+		want Filter
+	}{
+		{`a = 1`, a},
+		{`a=1`, a},
+		{"\ta =\n1 ", a},
+		{`published >= 1500000000`, FieldFilter("published >=", "1500000000")},
+		{`url ^ "https://"`, FieldFilter("url ^", "https://")},
+
+		// Precedence: AND binds more tightly than OR, NOT more tightly than both.
+		{`a = 1 OR b = 2 AND c = 3`, AnyFilter(a, AllFilters(b, c))},
+		{`a = 1 AND b = 2 OR c = 3`, AnyFilter(AllFilters(a, b), c)},
+		{`a = 1 and b = 2 or c = 3`, AnyFilter(AllFilters(a, b), c)},
+		{`a = 1 OR b = 2 OR c = 3`, AnyFilter(a, b, c)},
+		{`NOT a = 1 AND b = 2`, AllFilters(NoneOfFilters(a), b)},
+		{`not not a = 1`, NoneOfFilters(NoneOfFilters(a))},
+
+		// Parentheses.
+		{`(a = 1 OR b = 2) AND c = 3`, AllFilters(AnyFilter(a, b), c)},
+		{`NOT (a = 1 OR b = 2)`, NoneOfFilters(AnyFilter(a, b))},
+		{`((a = 1))`, a},
+
+		// Quoting and escapes.
+		{`title ~ "hello (world)"`, FieldFilter("title ~", "hello (world)")},
+		{`title = "say \"hi\""`, FieldFilter("title =", `say "hi"`)},
+		{`title = "a\tb"`, FieldFilter("title =", "a\tb")},
+		{`title = "AND"`, FieldFilter("title =", "AND")},
+		{`url ^ "https://a.com/?x=1"`, FieldFilter("url ^", "https://a.com/?x=1")},
+		{`title = ""`, FieldFilter("title =", "")},
+
+		// Non-ASCII values.
+		{`city = Bogotà`, FieldFilter("city =", "Bogotà")},
+		{`name = Åsa`, FieldFilter("name =", "Åsa")},
+		{`name=Åsa AND city = "São Paulo"`, AllFilters(FieldFilter("name =", "Åsa"), FieldFilter("city =", "São Paulo"))},
+		{"city = 東京", FieldFilter("city =", "東京")},
+	}
+
+	for _, tt := range tests {
+		got, err := ParseFilter(tt.s)
+		if err != nil {
+			t.Errorf("ParseFilter(%q) error: %v", tt.s, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("ParseFilter(%q) = %#v, expected %#v", tt.s, got, tt.want)
+		}
+	}
+}
+
+func TestParseFilterErrors(t *testing.T) {
+	tests := []struct {
+		s   string
+		err string
+	}{
+		{``, `filter: at position 1: expected field name, got end of filter`},
+		{`a = 1 b = 2`, `filter: at position 7: unexpected "b"`},
+		{`a = 1)`, `filter: at position 6: unexpected ")"`},
+		{`a 1`, `filter: at position 3: expected operator after "a", got "1"`},
+		{`a => 1`, `filter: at position 3: invalid operator "=>"`},
+		{`a =`, `filter: at position 4: expected value after "a =", got end of filter`},
+		{`a = 1 AND`, `filter: at position 10: expected field name, got end of filter`},
+		{`OR a = 1`, `filter: at position 1: expected field name, got "OR"`},
+		{`(a = 1`, `filter: at position 7: expected ), got end of filter`},
+		{`a = "x`, `filter: at position 5: unterminated string`},
+		{`a = "\q"`, `filter: at position 5: invalid string: invalid syntax`},
+		{`url ^ https://a.com/?x=1`, `filter: at position 23: unexpected "="`},
+		{`Åsa = 1 x`, `filter: at position 10: unexpected "x"`},
+	}
+
+	for _, tt := range tests {
+		_, err := ParseFilter(tt.s)
+		if err == nil {
+			t.Errorf("ParseFilter(%q) expected error %q", tt.s, tt.err)
+			continue
+		}
+		if err.Error() != tt.err {
+			t.Errorf("ParseFilter(%q) error = %q, expected %q", tt.s, err, tt.err)
+		}
+	}
+}
@@ -14,7 +14,7 @@ func (c *Client) Pipeline(name string) *Pipeline {
 	}
 }
 
-// Pipeline is a handler for a named pipeline.
+// Pipeline is a handler for a named pipeline.  It is safe for concurrent use.
 type Pipeline struct {
 	name string
 
@@ -25,6 +25,26 @@ type Pipeline struct {
 // tracking configuration.  Returns the query results and returned values (which could have
 // been modified in the pipeline).
 func (p *Pipeline) Search(ctx context.Context, values map[string]string, tracking Tracking) (*Results, map[string]string, error) {
+	return p.search(ctx, values, tracking, nil)
+}
+
+// SearchInto runs a search query defined by a pipeline like Search, but decodes result values
+// directly into dst instead of Result.Values.  See Query.SearchInto for details of how values
+// are decoded.
+func (p *Pipeline) SearchInto(ctx context.Context, values map[string]string, tracking Tracking, dst interface{}) (*Results, map[string]string, error) {
+	dec, err := newResultDecoder(dst)
+	if err != nil {
+		return nil, nil, err
+	}
+	return p.search(ctx, values, tracking, dec)
+}
+
+func (p *Pipeline) search(ctx context.Context, values map[string]string, tracking Tracking, dec *resultDecoder) (*Results, map[string]string, error) {
+	values, err := p.c.runPipelineHooks(values)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	pbTracking, err := tracking.proto()
 	if err != nil {
 		return nil, nil, err
@@ -38,12 +58,25 @@ func (p *Pipeline) Search(ctx context.Context, values map[string]string, trackin
 		Values:   values,
 	}
 
-	resp, err := piplinepb.NewQueryClient(p.c.ClientConn).Search(p.c.newContext(ctx), r)
+	v, err := p.c.hedge(ctx, func(ctx context.Context) (interface{}, error) {
+		return piplinepb.NewQueryClient(p.c.ClientConn).Search(p.c.newContext(ctx), r)
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	resp := v.(*piplinepb.SearchResponse)
+
+	results, err := p.c.processResponse(resp.SearchResponse, resp.Tokens, nil, dec)
 	if err != nil {
 		return nil, nil, err
 	}
+	if dec == nil {
+		if err := p.c.convertResultTimestamps(ctx, results, nil); err != nil {
+			return nil, nil, err
+		}
+	}
 
-	results, err := processResponse(resp.SearchResponse, resp.Tokens)
+	results, err = p.c.runResultHooks(results)
 	if err != nil {
 		return nil, nil, err
 	}
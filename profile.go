@@ -0,0 +1,70 @@
+package sajari
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ProfileTrackingKey is the key in Tracking.Data which records the name of the profile used
+// by a Request (see Request.Profile).
+const ProfileTrackingKey = "profile"
+
+// Profile is a named set of boosts which can be selected by a Request (see
+// Client.RegisterProfile and Request.Profile).
+type Profile struct {
+	// FieldBoosts are appended to IndexQuery.FieldBoosts.
+	FieldBoosts []FieldBoost
+
+	// InstanceBoosts are appended to IndexQuery.InstanceBoosts.
+	InstanceBoosts []InstanceBoost
+
+	// FeatureBoosts are appended to FeatureQuery.FieldBoosts.
+	FeatureBoosts []FeatureFieldBoost
+}
+
+// profiles is a registry of named profiles.
+type profiles struct {
+	mu sync.RWMutex
+	m  map[string]Profile
+}
+
+// RegisterProfile registers the profile p with name, replacing any existing profile with the
+// same name.  Requests select a profile by setting Request.Profile.  It is safe to register
+// profiles while searches are running.  Profiles are shared with Clients created using For.
+func (c *Client) RegisterProfile(name string, p Profile) {
+	c.profiles.mu.Lock()
+	defer c.profiles.mu.Unlock()
+
+	if c.profiles.m == nil {
+		c.profiles.m = make(map[string]Profile)
+	}
+	c.profiles.m[name] = p
+}
+
+// applyProfile returns a copy of r with the boosts of the profile selected by r.Profile
+// added.  If r doesn't select a profile then r is returned.
+func (c *Client) applyProfile(r *Request) (*Request, error) {
+	if r.Profile == "" {
+		return r, nil
+	}
+
+	c.profiles.mu.RLock()
+	p, ok := c.profiles.m[r.Profile]
+	c.profiles.mu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("sajari: unknown profile %q", r.Profile)
+	}
+
+	rc := r.Clone()
+	rc.IndexQuery.FieldBoosts = append(rc.IndexQuery.FieldBoosts, p.FieldBoosts...)
+	rc.IndexQuery.InstanceBoosts = append(rc.IndexQuery.InstanceBoosts, p.InstanceBoosts...)
+	rc.FeatureQuery.FieldBoosts = append(rc.FeatureQuery.FieldBoosts, p.FeatureBoosts...)
+
+	if rc.Tracking.Type != TrackingNone {
+		if rc.Tracking.Data == nil {
+			rc.Tracking.Data = make(map[string]string, 1)
+		}
+		rc.Tracking.Data[ProfileTrackingKey] = r.Profile
+	}
+	return &rc, nil
+}
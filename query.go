@@ -9,27 +9,214 @@ import (
 
 // Query returns a handler for running queries using the Client.
 func (c *Client) Query() *Query {
-	return &Query{c}
+	return &Query{c: c}
 }
 
-// Query is a handler which runs queries on a collection.
+// Query is a handler which runs queries on a collection.  It is safe for concurrent use.
+//
+// A Request can be shared between concurrent searches as long as it is not modified while
+// in use: use Request.Clone to make variations of a shared Request.
 type Query struct {
 	c *Client
+
+	// tracking holds the defaults set by WithTracking, if any.
+	tracking *Tracking
+}
+
+// WithTracking returns a copy of the Query which uses t as the default tracking for each
+// Request.  Requests which don't set Tracking.Type use the type of t, and unset Field,
+// QueryID and Sequence values are taken from t.  Data is merged, with values in the
+// Request replacing those in t.
+//
+//	q := client.Query().WithTracking(sajari.Tracking{
+//		Type:  sajari.TrackingClick,
+//		Field: "url",
+//		Data:  map[string]string{"service": "web"},
+//	})
+func (q *Query) WithTracking(t Tracking) *Query {
+	t.Data = copyStringMap(t.Data)
+	return &Query{
+		c:        q.c,
+		tracking: &t,
+	}
+}
+
+// applyTracking returns a copy of r with the default tracking (see WithTracking) merged
+// into r.Tracking.  If no defaults are set then r is returned.
+func (q *Query) applyTracking(r *Request) *Request {
+	if q.tracking == nil {
+		return r
+	}
+	d := q.tracking
+
+	rc := r.Clone()
+	t := &rc.Tracking
+	if t.Type == TrackingNone {
+		t.Type = d.Type
+	}
+	if t.Field == "" {
+		t.Field = d.Field
+	}
+	if t.QueryID == "" {
+		t.QueryID = d.QueryID
+	}
+	if t.Sequence == 0 {
+		t.Sequence = d.Sequence
+	}
+	if len(d.Data) > 0 {
+		data := copyStringMap(d.Data)
+		for k, v := range t.Data {
+			data[k] = v
+		}
+		t.Data = data
+	}
+	return &rc
 }
 
 // Search performs an engine search with the Request r, returning a set of Results and non-nil error
 // if there was a problem.
 func (q *Query) Search(ctx context.Context, r *Request) (*Results, error) {
+	r, pr, aliases, err := q.prepare(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := q.search(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	results, err := q.c.processResponse(resp.SearchResponse, resp.Tokens, aliases, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := q.c.convertResultTimestamps(ctx, results, aliases); err != nil {
+		return nil, err
+	}
+	if r.SkipResults {
+		results.Results = nil
+	}
+	return q.c.runResultHooks(results)
+}
+
+// SearchInto performs an engine search with the Request r, decoding result values directly
+// into dst instead of Result.Values.  The destination dst must be a pointer to a slice of structs
+// (or pointers to structs), which is reset to hold one element per result.
+//
+// Struct fields are matched to record fields by the `sajari:"name"` tag, or by the struct field
+// name if no tag is set.  Use `sajari:"-"` to ignore a field.  Values are converted to the type of
+// the struct field (string, bool, numeric, time.Time, slices of these or interface{}).
+//
+// The returned Results contain scores and tokens for each result, but Result.Values is nil.
+func (q *Query) SearchInto(ctx context.Context, r *Request, dst interface{}) (*Results, error) {
+	dec, err := newResultDecoder(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	r, pr, aliases, err := q.prepare(r)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := q.search(ctx, pr)
+	if err != nil {
+		return nil, err
+	}
+	results, err := q.c.processResponse(resp.SearchResponse, resp.Tokens, aliases, dec)
+	if err != nil {
+		return nil, err
+	}
+	if r.SkipResults {
+		results.Results = nil
+		dec.reset(0)
+	}
+	return q.c.runResultHooks(results)
+}
+
+// prepare applies the default tracking, query hooks, profile and archive filter to r (see
+// WithTracking, WithQueryHook, Request.Profile and WithArchivedExcluded), returning the
+// resulting Request, its proto and the field aliases to apply to results.
+func (q *Query) prepare(r *Request) (*Request, *pb.SearchRequest, fieldAliases, error) {
+	r = q.applyTracking(r)
+
+	r, err := q.c.runQueryHooks(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	r, err = q.c.applyProfile(r)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	r = q.c.excludeArchived(r)
+
 	pr, err := r.proto()
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	if r.SkipResults {
+		skipResults(pr)
+	}
+
+	_, aliases, err := parseFields(r.Fields)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return r, pr, aliases, nil
+}
+
+// search runs the search request pr, using the query cache, coalescing concurrent
+// identical searches and hedging if they are enabled.
+func (q *Query) search(ctx context.Context, pr *pb.SearchRequest) (*pb.SearchResponse, error) {
+	qc := q.c.queryCache
+	if skipQueryCache(ctx) || hasTracking(pr) {
+		qc = nil
+	}
+	sg := q.c.searchGroup
+	if hasTracking(pr) {
+		sg = nil
+	}
+
+	search := func() (*pb.SearchResponse, error) {
+		resp, err := q.c.hedge(ctx, func(ctx context.Context) (interface{}, error) {
+			return pb.NewQueryClient(q.c.ClientConn).Search(q.c.newContext(ctx), pr)
+		})
+		if err != nil {
+			return nil, err
+		}
+		return resp.(*pb.SearchResponse), nil
+	}
+
+	if qc == nil && sg == nil {
+		return search()
+	}
+
+	key, err := queryCacheKey(pr)
 	if err != nil {
 		return nil, err
 	}
 
-	resp, err := pb.NewQueryClient(q.c.ClientConn).Search(q.c.newContext(ctx), pr)
+	if qc != nil {
+		if resp := qc.get(key); resp != nil {
+			return resp, nil
+		}
+	}
+
+	var resp *pb.SearchResponse
+	if sg != nil {
+		resp, err = sg.do(key, search)
+	} else {
+		resp, err = search()
+	}
 	if err != nil {
 		return nil, err
 	}
-	return processResponse(resp.SearchResponse, resp.Tokens)
+
+	if qc != nil {
+		qc.set(key, resp)
+	}
+	return resp, nil
 }
 
 // AnalyseMulti performs Analysis on multiple records against the same query request.
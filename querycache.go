@@ -0,0 +1,122 @@
+package sajari
+
+import (
+	"container/list"
+	"sync"
+	"time"
+
+	"github.com/golang/protobuf/jsonpb"
+	"golang.org/x/net/context"
+
+	pb "code.sajari.com/protogen-go/sajari/api/query/v1"
+)
+
+// WithQueryCache configures the client to cache the responses of up to size distinct
+// searches for ttl.  Identical searches (with the same Request) made within ttl of a
+// cached response are answered from the cache without making a request.  When the
+// cache is full the least recently used response is evicted.
+//
+// Use SkipQueryCache to bypass the cache for individual searches.  The cache is used
+// by Query.Search and Query.SearchInto.  Searches with tracking enabled bypass the cache,
+// as their tracking tokens must not be shared between callers.
+func WithQueryCache(size int, ttl time.Duration) Opt {
+	return func(c *Client) {
+		c.queryCache = &queryCache{
+			size:  size,
+			ttl:   ttl,
+			ll:    list.New(),
+			items: make(map[string]*list.Element, size),
+		}
+	}
+}
+
+type skipQueryCacheKey struct{}
+
+// SkipQueryCache returns a context which causes searches to bypass the query cache
+// (see WithQueryCache).  The response is not stored in the cache.
+func SkipQueryCache(ctx context.Context) context.Context {
+	return context.WithValue(ctx, skipQueryCacheKey{}, true)
+}
+
+// hasTracking returns true if pr requests tracking tokens, which are specific to each
+// search and so must not be shared with other callers.
+func hasTracking(pr *pb.SearchRequest) bool {
+	return pr.Tracking != nil && pr.Tracking.Type != pb.SearchRequest_Tracking_NONE
+}
+
+func skipQueryCache(ctx context.Context) bool {
+	skip, _ := ctx.Value(skipQueryCacheKey{}).(bool)
+	return skip
+}
+
+// queryCache is an LRU cache of search responses keyed on the serialised request.
+type queryCache struct {
+	size int
+	ttl  time.Duration
+
+	mu    sync.Mutex
+	ll    *list.List
+	items map[string]*list.Element
+}
+
+type queryCacheEntry struct {
+	key     string
+	resp    *pb.SearchResponse
+	expires time.Time
+}
+
+// queryCacheKey returns the cache key for the request.  JSON is used as it is
+// deterministic (map keys are sorted).
+func queryCacheKey(r *pb.SearchRequest) (string, error) {
+	return (&jsonpb.Marshaler{}).MarshalToString(r)
+}
+
+// get returns the cached response for key, or nil if there is no unexpired entry.
+func (qc *queryCache) get(key string) *pb.SearchResponse {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	e, ok := qc.items[key]
+	if !ok {
+		return nil
+	}
+
+	entry := e.Value.(*queryCacheEntry)
+	if time.Now().After(entry.expires) {
+		qc.ll.Remove(e)
+		delete(qc.items, key)
+		return nil
+	}
+	qc.ll.MoveToFront(e)
+	return entry.resp
+}
+
+func (qc *queryCache) set(key string, resp *pb.SearchResponse) {
+	qc.mu.Lock()
+	defer qc.mu.Unlock()
+
+	if qc.size <= 0 {
+		return
+	}
+
+	expires := time.Now().Add(qc.ttl)
+	if e, ok := qc.items[key]; ok {
+		entry := e.Value.(*queryCacheEntry)
+		entry.resp = resp
+		entry.expires = expires
+		qc.ll.MoveToFront(e)
+		return
+	}
+
+	qc.items[key] = qc.ll.PushFront(&queryCacheEntry{
+		key:     key,
+		resp:    resp,
+		expires: expires,
+	})
+
+	for qc.ll.Len() > qc.size {
+		e := qc.ll.Back()
+		qc.ll.Remove(e)
+		delete(qc.items, e.Value.(*queryCacheEntry).key)
+	}
+}
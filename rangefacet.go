@@ -0,0 +1,135 @@
+package sajari
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+
+	pb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+)
+
+// Range is a range of numeric values used in a RangeFacet.  Values are in the range if
+// they are greater than or equal to From and less than To.  Use math.Inf(-1) or math.Inf(1)
+// for a range which is unbounded below or above.
+type Range struct {
+	// Name of the range, which must be unique within the facet.  If empty then a name is
+	// generated from the bounds, e.g. "10-20", "*-10" or "20-*".
+	Name string
+
+	// From is the (inclusive) lower bound of the range.
+	From float64
+
+	// To is the (exclusive) upper bound of the range.
+	To float64
+}
+
+func (r Range) name() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	return formatBound(r.From) + "-" + formatBound(r.To)
+}
+
+func formatBound(x float64) string {
+	if math.IsInf(x, 0) {
+		return "*"
+	}
+	return strconv.FormatFloat(x, 'g', -1, 64)
+}
+
+// filter returns the filter which matches values of field in the range.
+func (r Range) filter(field string) (Filter, error) {
+	if math.IsNaN(r.From) || math.IsNaN(r.To) || r.From >= r.To {
+		return nil, fmt.Errorf("invalid range %v: From must be less than To", r.name())
+	}
+
+	var fs []Filter
+	if !math.IsInf(r.From, -1) {
+		fs = append(fs, FieldFilter(field+" >=", r.From))
+	}
+	if !math.IsInf(r.To, 1) {
+		fs = append(fs, FieldFilter(field+" <", r.To))
+	}
+	switch len(fs) {
+	case 0:
+		return nil, fmt.Errorf("invalid range %v: must have at least one bound", r.name())
+	case 1:
+		return fs[0], nil
+	}
+	return AllFilters(fs...), nil
+}
+
+// RangeFacet returns an aggregate which counts the records with values of the numeric field
+// in each of the ranges.  Use RangeFacetAggregate.Buckets to get the counts from the Results.
+//
+//	f := sajari.RangeFacet("price",
+//		sajari.Range{From: math.Inf(-1), To: 10},
+//		sajari.Range{From: 10, To: 50},
+//		sajari.Range{From: 50, To: math.Inf(1)},
+//	)
+//	r.Aggregates = map[string]sajari.Aggregate{"price": f}
+//	...
+//	buckets, err := f.Buckets(results.Aggregates["price"])
+func RangeFacet(field string, ranges ...Range) *RangeFacetAggregate {
+	return &RangeFacetAggregate{
+		field:  field,
+		ranges: ranges,
+	}
+}
+
+// RangeFacetAggregate is an aggregate which counts records in numeric ranges.  Use RangeFacet
+// to create one.
+type RangeFacetAggregate struct {
+	field  string
+	ranges []Range
+}
+
+func (a *RangeFacetAggregate) proto() (*pb.Aggregate, error) {
+	seen := make(map[string]bool, len(a.ranges))
+	bs := make([]Bucket, 0, len(a.ranges))
+	for _, r := range a.ranges {
+		name := r.name()
+		if seen[name] {
+			return nil, fmt.Errorf("range facet on %q: duplicate range name %q", a.field, name)
+		}
+		seen[name] = true
+
+		f, err := r.filter(a.field)
+		if err != nil {
+			return nil, fmt.Errorf("range facet on %q: %v", a.field, err)
+		}
+		bs = append(bs, Bucket{
+			Name:   name,
+			Filter: f,
+		})
+	}
+	return bucketAggregate{bs}.proto()
+}
+
+// RangeBucket is the count of records in a Range.
+type RangeBucket struct {
+	Range
+
+	// Count is the number of records with values in the range.
+	Count int
+}
+
+// Buckets returns the counts for each range (in the order passed to RangeFacet) from
+// the aggregate response v, the value in Results.Aggregates for this aggregate.
+func (a *RangeFacetAggregate) Buckets(v interface{}) ([]RangeBucket, error) {
+	br, ok := v.(BucketsResponse)
+	if !ok {
+		return nil, fmt.Errorf("range facet on %q: expected BucketsResponse, got %T", a.field, v)
+	}
+
+	out := make([]RangeBucket, 0, len(a.ranges))
+	for _, r := range a.ranges {
+		name := r.name()
+		r.Name = name
+		out = append(out, RangeBucket{
+			Range: r,
+			Count: br[name].Count,
+		})
+	}
+	return out, nil
+}
@@ -1,8 +1,13 @@
 package sajari
 
 import (
+	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"google.golang.org/grpc"
@@ -30,6 +35,10 @@ const (
 // ErrNoSuchRecord is returned when a requested record cannot be found.
 var ErrNoSuchRecord = errors.New("sajari: no such record")
 
+// ErrNotAdded is set by AddMulti for records which were valid, but were not added because
+// other records in the same call contain values of unsupported types (see ValueErrors).
+var ErrNotAdded = errors.New("sajari: record not added: other records have unsupported values")
+
 // Record is a set of key-value pairs.
 type Record map[string]interface{}
 
@@ -43,24 +52,45 @@ func NewRecord(body string, values map[string]interface{}) Record {
 	return d
 }
 
-func valueFromProto(v *enginepb.Value) (interface{}, error) {
+// valueFromProto converts v into a string or []string.  Repeated values are copied
+// unless borrow is set, in which case the returned slice is owned by v.
+func valueFromProto(v *enginepb.Value, borrow bool) (interface{}, error) {
 	switch v := v.Value.(type) {
 	case *enginepb.Value_Single:
 		return v.Single, nil
 
 	case *enginepb.Value_Repeated_:
-		return v.Repeated.Values, nil
+		if borrow {
+			return v.Repeated.Values, nil
+		}
+		vs := make([]string, len(v.Repeated.Values))
+		copy(vs, v.Repeated.Values)
+		return vs, nil
 	}
 	return nil, fmt.Errorf("unexpected type: %T", v)
 }
 
+// formatSingleValue returns the engine representation of the single value x.  Floats are
+// formatted in decimal notation with the minimum number of digits needed to represent
+// them exactly, as exponent notation (e.g. 1e+21) would not be parsed by the engine.
+func formatSingleValue(x interface{}) string {
+	switch x := x.(type) {
+	case float64:
+		return strconv.FormatFloat(x, 'f', -1, 64)
+
+	case float32:
+		return strconv.FormatFloat(float64(x), 'f', -1, 32)
+	}
+	return fmt.Sprintf("%v", x)
+}
+
 func pbSingleValue(x interface{}) (*enginepb.Value, error) {
 	switch x := x.(type) {
 	case int, uint, int64, uint64, int32, uint32, int16, uint16,
 		int8, uint8, float32, float64, string, bool:
 		return &enginepb.Value{
 			Value: &enginepb.Value_Single{
-				Single: fmt.Sprintf("%v", x),
+				Single: formatSingleValue(x),
 			},
 		}, nil
 	}
@@ -73,13 +103,13 @@ func pbValueFromInterface(x interface{}) (*enginepb.Value, error) {
 		int8, uint8, float32, float64, string, bool:
 		return &enginepb.Value{
 			Value: &enginepb.Value_Single{
-				Single: fmt.Sprintf("%v", x),
+				Single: formatSingleValue(x),
 			},
 		}, nil
 	case time.Time:
 		return &enginepb.Value{
 			Value: &enginepb.Value_Single{
-				Single: fmt.Sprintf("%v", x.Unix()),
+				Single: formatTimestamp(x),
 			},
 		}, nil
 	}
@@ -101,6 +131,18 @@ func pbValueFromInterface(x interface{}) (*enginepb.Value, error) {
 			vs = append(vs, fmt.Sprintf("%v", v))
 		}
 
+	case []float64:
+		vs = make([]string, 0, len(x))
+		for _, v := range x {
+			vs = append(vs, formatSingleValue(v))
+		}
+
+	case []time.Time:
+		vs = make([]string, 0, len(x))
+		for _, v := range x {
+			vs = append(vs, formatTimestamp(v))
+		}
+
 	default:
 		return nil, fmt.Errorf("unsupported value: %T", x)
 	}
@@ -116,15 +158,23 @@ func pbValueFromInterface(x interface{}) (*enginepb.Value, error) {
 
 type protoValues map[string]interface{}
 
+// proto converts the values in p, returning ValueErrors listing every value which could
+// not be converted.
 func (p protoValues) proto() (map[string]*enginepb.Value, error) {
 	values := make(map[string]*enginepb.Value, len(p))
+	var errs ValueErrors
 	for k, v := range p {
 		vv, err := pbValueFromInterface(v)
 		if err != nil {
-			return nil, err
+			errs = append(errs, &ValueError{Field: k, Value: v})
+			continue
 		}
 		values[k] = vv
 	}
+	if errs != nil {
+		sort.Sort(valueErrorsByField(errs))
+		return nil, errs
+	}
 	return values, nil
 }
 
@@ -140,16 +190,32 @@ func (r Record) proto() (*pb.Record, error) {
 
 type records []Record
 
+// proto converts rs.  If any records can't be converted then it returns a MultiError with
+// their errors set in the respective indexes, and ErrNotAdded set for the other records.
 func (rs records) proto() ([]*pb.Record, error) {
 	pbrs := make([]*pb.Record, 0, len(rs))
-	for _, r := range rs {
+	var me MultiError
+	for i, r := range rs {
 		pbr, err := r.proto()
 		if err != nil {
-			return nil, err
+			if me == nil {
+				me = make(MultiError, len(rs))
+			}
+			me[i] = err
+			continue
 		}
 		pbrs = append(pbrs, pbr)
 	}
-	return pbrs, nil
+	if me == nil {
+		return pbrs, nil
+	}
+
+	for i, err := range me {
+		if err == nil {
+			me[i] = ErrNotAdded
+		}
+	}
+	return nil, me
 }
 
 // Key is a unique identifier for a stored record.
@@ -167,6 +233,65 @@ func NewKey(field string, value interface{}) *Key {
 	}
 }
 
+// Field returns the name of the (unique) field which identifies the record.
+func (k *Key) Field() string {
+	if k == nil {
+		return ""
+	}
+	return k.field
+}
+
+// Value returns the value of the key field.
+func (k *Key) Value() interface{} {
+	if k == nil {
+		return nil
+	}
+	return k.value
+}
+
+// keyJSON is the JSON representation of a Key.
+type keyJSON struct {
+	Field string      `json:"field"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler.  Keys are encoded as an object with
+// "field" and "value" properties.
+func (k *Key) MarshalJSON() ([]byte, error) {
+	return json.Marshal(keyJSON{
+		Field: k.field,
+		Value: k.value,
+	})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  Numeric values are decoded as strings,
+// so that large integers are not rounded.
+func (k *Key) UnmarshalJSON(b []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+
+	var kj keyJSON
+	if err := dec.Decode(&kj); err != nil {
+		return err
+	}
+	if kj.Field == "" {
+		return fmt.Errorf("sajari: key has no field")
+	}
+
+	switch v := kj.Value.(type) {
+	case json.Number:
+		kj.Value = string(v)
+
+	case string, bool:
+
+	default:
+		return fmt.Errorf("sajari: key %q has invalid value %v", kj.Field, kj.Value)
+	}
+
+	k.field, k.value = kj.Field, kj.Value
+	return nil
+}
+
 // String implements Stringer.
 func (k *Key) String() string {
 	if k == nil {
@@ -207,7 +332,7 @@ func keyFromProto(k *enginepb.Key) (*Key, error) {
 	if k.Field == "" && k.Value == nil {
 		return nil, nil
 	}
-	val, err := valueFromProto(k.Value)
+	val, err := valueFromProto(k.Value, false)
 	if err != nil {
 		return nil, err
 	}
@@ -281,8 +406,45 @@ func (me MultiError) Error() string {
 	return fmt.Sprintf("%v (and %d other errors)", msg, n)
 }
 
+// ValueError is returned when a value can't be sent to the engine because its Go type is
+// not supported (see Record).
+type ValueError struct {
+	// Field is the name of the field the value was set on.
+	Field string
+
+	// Value is the offending value.
+	Value interface{}
+}
+
+// Error implements error.
+func (e *ValueError) Error() string {
+	return fmt.Sprintf("sajari: field %q: unsupported value type %T", e.Field, e.Value)
+}
+
+// ValueErrors is returned when a Record contains values with unsupported types, and lists
+// each offending value ordered by field name.  AddMulti returns a MultiError with a
+// ValueErrors set in the index of each record which could not be converted, and adds none
+// of the records (see ErrNotAdded).
+type ValueErrors []*ValueError
+
+// Error implements error.
+func (es ValueErrors) Error() string {
+	fields := make([]string, 0, len(es))
+	for _, e := range es {
+		fields = append(fields, fmt.Sprintf("%q (%T)", e.Field, e.Value))
+	}
+	return fmt.Sprintf("sajari: unsupported value types for fields %v", strings.Join(fields, ", "))
+}
+
+type valueErrorsByField ValueErrors
+
+func (es valueErrorsByField) Len() int           { return len(es) }
+func (es valueErrorsByField) Less(i, j int) bool { return es[i].Field < es[j].Field }
+func (es valueErrorsByField) Swap(i, j int)      { es[i], es[j] = es[j], es[i] }
+
 // Add adds a record to a collection, returning a key which can be used to retrieve the
-// record.  If no transforms are specified then DefaultAddTransforms is used.
+// record.  If no transforms are specified then the Client's default transforms are used
+// (see WithDefaultAddTransforms).
 func (c *Client) Add(ctx context.Context, r Record, ts ...Transform) (*Key, error) {
 	ks, err := c.AddMulti(ctx, []Record{r}, ts...)
 	if err != nil {
@@ -295,6 +457,8 @@ func (c *Client) Add(ctx context.Context, r Record, ts ...Transform) (*Key, erro
 }
 
 // DefaultAddTransforms is the default list of transforms which are used when adding records.
+// Use WithDefaultAddTransforms to configure the default for a Client rather than modifying
+// this variable: modifying it while records are being added is a data race.
 var DefaultAddTransforms = []Transform{
 	SplitStopStemIndexedFieldsTranform,
 }
@@ -302,7 +466,12 @@ var DefaultAddTransforms = []Transform{
 // AddMulti adds records to the underlying collection, returning a list of Keys which can be used
 // to retrieve the respective record.  If any of the adds fail then a MultiError will be returned
 // with errors set in the respective indexes.
-// If no transforms are specified then DefaultAddTransforms is used.
+// If no transforms are specified then the Client's default transforms are used (see
+// WithDefaultAddTransforms).
+//
+// If any record contains values of unsupported types then no records are added, and a
+// MultiError is returned with ValueErrors set in the indexes of those records and
+// ErrNotAdded set in the indexes of the others.
 func (c *Client) AddMulti(ctx context.Context, rs []Record, ts ...Transform) ([]*Key, error) {
 	pbrs, err := records(rs).proto()
 	if err != nil {
@@ -310,7 +479,18 @@ func (c *Client) AddMulti(ctx context.Context, rs []Record, ts ...Transform) ([]
 	}
 
 	if len(ts) == 0 {
-		ts = append(ts, DefaultAddTransforms...)
+		ts = c.defaultAddTransforms
+		if ts == nil {
+			ts = DefaultAddTransforms
+		}
+	}
+
+	if c.validateTransforms {
+		for _, t := range ts {
+			if err := t.Validate(); err != nil {
+				return nil, err
+			}
+		}
 	}
 
 	pbts := make([]*pb.Transform, 0, len(ts))
@@ -416,6 +596,36 @@ func (c *Client) Mutate(ctx context.Context, k *Key, m ...FieldMutation) error {
 	return err
 }
 
+// MutateAll applies the same field mutations ms to each of the records identified by ks in a
+// single request.  If any of the mutations fail then a MultiError will be returned with errors
+// set in the respective indexes.
+func (c *Client) MutateAll(ctx context.Context, ks []*Key, ms ...FieldMutation) error {
+	fms, err := fieldMutations(ms).proto()
+	if err != nil {
+		return err
+	}
+
+	rmspb := make([]*pb.MutateRequest_RecordMutation, 0, len(ks))
+	for _, k := range ks {
+		kpb, err := k.proto()
+		if err != nil {
+			return err
+		}
+		rmspb = append(rmspb, &pb.MutateRequest_RecordMutation{
+			Key:            kpb,
+			FieldMutations: fms,
+		})
+	}
+
+	resp, err := pb.NewStoreClient(c.ClientConn).Mutate(c.newContext(ctx), &pb.MutateRequest{
+		RecordMutations: rmspb,
+	})
+	if err != nil {
+		return err
+	}
+	return multiErrorFromRecordStatusProto(resp.Status)
+}
+
 // Delete removes the record identified by key k.
 func (c *Client) Delete(ctx context.Context, k *Key) error {
 	err := c.DeleteMulti(ctx, []*Key{k})
@@ -444,10 +654,10 @@ func (c *Client) DeleteMulti(ctx context.Context, ks []*Key) error {
 	return multiErrorFromRecordStatusProto(resp.Status)
 }
 
-func recordFromProto(pbr *pb.Record) (Record, error) {
+func recordFromProto(pbr *pb.Record, borrow bool) (Record, error) {
 	d := make(Record)
 	for k, v := range pbr.Values {
-		vv, err := valueFromProto(v)
+		vv, err := valueFromProto(v, borrow)
 		if err != nil {
 			return nil, err
 		}
@@ -458,10 +668,10 @@ func recordFromProto(pbr *pb.Record) (Record, error) {
 
 type pbRecords []*pb.Record
 
-func (pbrs pbRecords) records() ([]Record, error) {
+func (pbrs pbRecords) records(borrow bool) ([]Record, error) {
 	out := make([]Record, 0, len(pbrs))
 	for _, pbr := range pbrs {
-		d, err := recordFromProto(pbr)
+		d, err := recordFromProto(pbr, borrow)
 		if err != nil {
 			return nil, err
 		}
@@ -535,7 +745,24 @@ func (c *Client) ExistsMulti(ctx context.Context, k []*Key) ([]bool, error) {
 	return nil, MultiError(errs)
 }
 
-// GetMulti retrieves the records identified by the keys k.
+// ExistsAny returns true iff there is at least one record in the collection which matches
+// the filter f.  No record values are fetched (see Request.SkipResults), and the query cache
+// is bypassed (see SkipQueryCache).
+func (c *Client) ExistsAny(ctx context.Context, f Filter) (bool, error) {
+	resp, err := c.Query().Search(SkipQueryCache(ctx), &Request{
+		Filter:      f,
+		SkipResults: true,
+	})
+	if err != nil {
+		return false, err
+	}
+	return resp.TotalResults > 0, nil
+}
+
+// GetMulti retrieves the records identified by the keys k.  The returned slice has one
+// element for each key, in the same order as k.  If any of the records could not be
+// retrieved then a MultiError will be returned with errors set in the respective indexes
+// (ErrNoSuchRecord if the record does not exist), and the respective records will be nil.
 func (c *Client) GetMulti(ctx context.Context, k []*Key) ([]Record, error) {
 	pbks, err := keys(k).proto()
 	if err != nil {
@@ -549,11 +776,69 @@ func (c *Client) GetMulti(ctx context.Context, k []*Key) ([]Record, error) {
 		return nil, err
 	}
 
-	docs, err := pbRecords(resp.Records).records()
+	docs, err := pbRecords(resp.Records).records(c.borrowValues)
+	if err != nil {
+		return nil, err
+	}
+
+	ts, err := c.timestampFields(ctx)
 	if err != nil {
 		return nil, err
 	}
-	return docs, multiErrorFromRecordStatusProto(resp.Status)
+	for _, d := range docs {
+		if err := convertTimestamps(d, ts, nil); err != nil {
+			return nil, err
+		}
+	}
+
+	merr := multiErrorFromRecordStatusProto(resp.Status)
+	if merr == nil {
+		if len(docs) != len(k) {
+			return nil, fmt.Errorf("sajari: expected %d records, got %d", len(k), len(docs))
+		}
+		return docs, nil
+	}
+
+	me := merr.(MultiError)
+	if len(me) != len(k) {
+		return nil, fmt.Errorf("sajari: expected %d statuses, got %d", len(k), len(me))
+	}
+
+	out := make([]Record, len(k))
+	switch ok := countNil(me); len(docs) {
+	case len(k):
+		// Records are returned for each key, with empty records for errors.
+		for i, err := range me {
+			if err == nil {
+				out[i] = docs[i]
+			}
+		}
+
+	case ok:
+		// Records are only returned for keys which were found.
+		j := 0
+		for i, err := range me {
+			if err == nil {
+				out[i] = docs[j]
+				j++
+			}
+		}
+
+	default:
+		return nil, fmt.Errorf("sajari: expected %d records, got %d", ok, len(docs))
+	}
+	return out, me
+}
+
+// countNil returns the number of nil errors in me.
+func countNil(me MultiError) int {
+	n := 0
+	for _, err := range me {
+		if err == nil {
+			n++
+		}
+	}
+	return n
 }
 
 // SetFields converts the map of field-value pairs into field mutations
@@ -580,7 +865,7 @@ type setField struct {
 func (s setField) proto() (*pb.MutateRequest_RecordMutation_FieldMutation, error) {
 	v, err := pbValueFromInterface(s.value)
 	if err != nil {
-		return nil, err
+		return nil, &ValueError{Field: s.field, Value: s.value}
 	}
 
 	return &pb.MutateRequest_RecordMutation_FieldMutation{
@@ -0,0 +1,177 @@
+package sajari
+
+import (
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"golang.org/x/net/context"
+
+	enginepb "code.sajari.com/protogen-go/sajari/engine"
+	querypb "code.sajari.com/protogen-go/sajari/engine/query/v1"
+)
+
+var numericValueTests = []struct {
+	value interface{}
+	want  string
+}{
+	{1e21, "1000000000000000000000"},
+	{1e-7, "0.0000001"},
+	{0.1, "0.1"},
+	{3.0, "3"},
+	{-2.5, "-2.5"},
+	{float32(0.1), "0.1"},
+	{float32(1e21), "1000000000000000000000"},
+	{int64(1 << 53), "9007199254740992"},
+	{42, "42"},
+}
+
+func TestPbValueFromInterfaceNumeric(t *testing.T) {
+	for _, tt := range numericValueTests {
+		v, err := pbValueFromInterface(tt.value)
+		if err != nil {
+			t.Errorf("pbValueFromInterface(%v) error: %v", tt.value, err)
+			continue
+		}
+		got, err := valueFromProto(v, false)
+		if err != nil {
+			t.Errorf("valueFromProto(%v) error: %v", v, err)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("pbValueFromInterface(%v) = %q, want %q", tt.value, got, tt.want)
+		}
+	}
+}
+
+func TestFloatRoundTrip(t *testing.T) {
+	tests := []struct {
+		value float64
+		bits  int
+	}{
+		{1e21, 64},
+		{1e-7, 64},
+		{123456.789012345, 64},
+		{float64(float32(0.1)), 32},
+		{float64(float32(1e-7)), 32},
+	}
+
+	for _, tt := range tests {
+		var x interface{} = tt.value
+		if tt.bits == 32 {
+			x = float32(tt.value)
+		}
+
+		v, err := pbValueFromInterface(x)
+		if err != nil {
+			t.Errorf("pbValueFromInterface(%v) error: %v", x, err)
+			continue
+		}
+		s, err := valueFromProto(v, false)
+		if err != nil {
+			t.Errorf("valueFromProto(%v) error: %v", v, err)
+			continue
+		}
+		got, err := strconv.ParseFloat(s.(string), tt.bits)
+		if err != nil {
+			t.Errorf("ParseFloat(%q) error: %v", s, err)
+			continue
+		}
+		if got != tt.value {
+			t.Errorf("round trip of %v = %v", x, got)
+		}
+	}
+}
+
+func TestPbValueFromInterfaceRepeatedFloats(t *testing.T) {
+	v, err := pbValueFromInterface([]float64{1e21, 1e-7, 0.5})
+	if err != nil {
+		t.Fatalf("pbValueFromInterface() error: %v", err)
+	}
+	got, err := valueFromProto(v, false)
+	if err != nil {
+		t.Fatalf("valueFromProto() error: %v", err)
+	}
+	want := []string{"1000000000000000000000", "0.0000001", "0.5"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("valueFromProto() = %v, want %v", got, want)
+	}
+}
+
+func TestFieldFilterNumericValues(t *testing.T) {
+	for _, tt := range numericValueTests {
+		pbf, err := FieldFilter("price >=", tt.value).proto()
+		if err != nil {
+			t.Errorf("FieldFilter(%v).proto() error: %v", tt.value, err)
+			continue
+		}
+		ff, ok := pbf.Filter.(*querypb.Filter_Field_)
+		if !ok {
+			t.Errorf("FieldFilter(%v).proto() = %T, want field filter", tt.value, pbf.Filter)
+			continue
+		}
+		single, ok := ff.Field.Value.Value.(*enginepb.Value_Single)
+		if !ok {
+			t.Errorf("FieldFilter(%v) value = %T, want single value", tt.value, ff.Field.Value.Value)
+			continue
+		}
+		if single.Single != tt.want {
+			t.Errorf("FieldFilter(%v) value = %q, want %q", tt.value, single.Single, tt.want)
+		}
+	}
+}
+
+func TestRecordJSONFloats(t *testing.T) {
+	r := Record{"prices": []float64{1e21, 1e-7, 0.5}}
+	b, err := json.Marshal(r)
+	if err != nil {
+		t.Fatalf("json.Marshal() error: %v", err)
+	}
+
+	var got Record
+	if err := json.Unmarshal(b, &got); err != nil {
+		t.Fatalf("json.Unmarshal(%s) error: %v", b, err)
+	}
+	if !reflect.DeepEqual(got, r) {
+		t.Errorf("round trip of %v = %v", r, got)
+	}
+}
+
+func TestAddMultiUnsupportedValues(t *testing.T) {
+	// The client has no connection: the records must be rejected before anything is sent.
+	c := &Client{}
+	rs := []Record{
+		{"title": "ok"},
+		{"title": "bad", "a": struct{}{}, "b": []bool{true}},
+	}
+
+	ks, err := c.AddMulti(context.Background(), rs)
+	if ks != nil {
+		t.Errorf("AddMulti() keys = %v, expected nil", ks)
+	}
+	me, ok := err.(MultiError)
+	if !ok || len(me) != len(rs) {
+		t.Fatalf("AddMulti() error = %#v, expected a MultiError of length %d", err, len(rs))
+	}
+	if me[0] != ErrNotAdded {
+		t.Errorf("AddMulti() error[0] = %v, expected ErrNotAdded", me[0])
+	}
+	ves, ok := me[1].(ValueErrors)
+	if !ok {
+		t.Fatalf("AddMulti() error[1] = %#v, expected ValueErrors", me[1])
+	}
+	var fields []string
+	for _, e := range ves {
+		fields = append(fields, e.Field)
+	}
+	if want := []string{"a", "b"}; !reflect.DeepEqual(fields, want) {
+		t.Errorf("ValueErrors fields = %v, expected %v", fields, want)
+	}
+
+	if _, err := c.Add(context.Background(), rs[1]); err == nil {
+		t.Errorf("Add() with unsupported values: expected error")
+	} else if _, ok := err.(ValueErrors); !ok {
+		t.Errorf("Add() error = %#v, expected ValueErrors", err)
+	}
+}
@@ -0,0 +1,260 @@
+package sajari
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// Type hints used in the JSON encoding of a Record.
+const (
+	jsonTypeString     = "string"
+	jsonTypeInt        = "int"
+	jsonTypeUint       = "uint"
+	jsonTypeFloat      = "float"
+	jsonTypeBool       = "bool"
+	jsonTypeTimestamp  = "timestamp"
+	jsonTypeStrings    = "strings"
+	jsonTypeInts       = "ints"
+	jsonTypeFloats     = "floats"
+	jsonTypeTimestamps = "timestamps"
+)
+
+// recordValueJSON is the JSON representation of a Record value.
+type recordValueJSON struct {
+	Type  string      `json:"type"`
+	Value interface{} `json:"value"`
+}
+
+// MarshalJSON implements json.Marshaler.  Each value is encoded as an object with a
+// "type" hint and the "value", so that UnmarshalJSON can decode each value as the same
+// kind of value:
+//
+//	{"title": {"type": "string", "value": "Hello"}, "count": {"type": "int", "value": "42"}}
+//
+// UnmarshalJSON returns normalised types rather than the original Go types: signed
+// integers are decoded as int64, unsigned integers as uint64, floats as float64, and []int
+// and []int64 as []int64.  Strings, bools, []string, []float64, time.Time and []time.Time
+// keep their types, though times are decoded in a fixed zone with the encoded offset.
+//
+// Integers are encoded as strings so that they are not rounded by other JSON decoders,
+// and timestamps are encoded in RFC 3339 format.
+func (r Record) MarshalJSON() ([]byte, error) {
+	out := make(map[string]recordValueJSON, len(r))
+	for k, v := range r {
+		rv, err := recordValueToJSON(v)
+		if err != nil {
+			return nil, fmt.Errorf("sajari: field %q: %v", k, err)
+		}
+		out[k] = rv
+	}
+	return json.Marshal(out)
+}
+
+func recordValueToJSON(x interface{}) (recordValueJSON, error) {
+	switch x := x.(type) {
+	case string:
+		return recordValueJSON{jsonTypeString, x}, nil
+
+	case int, int64, int32, int16, int8:
+		return recordValueJSON{jsonTypeInt, fmt.Sprintf("%d", x)}, nil
+
+	case uint, uint64, uint32, uint16, uint8:
+		return recordValueJSON{jsonTypeUint, fmt.Sprintf("%d", x)}, nil
+
+	case float32:
+		return recordValueJSON{jsonTypeFloat, float64(x)}, nil
+
+	case float64:
+		return recordValueJSON{jsonTypeFloat, x}, nil
+
+	case bool:
+		return recordValueJSON{jsonTypeBool, x}, nil
+
+	case time.Time:
+		return recordValueJSON{jsonTypeTimestamp, x.Format(time.RFC3339Nano)}, nil
+
+	case []string:
+		return recordValueJSON{jsonTypeStrings, x}, nil
+
+	case []int:
+		vs := make([]string, 0, len(x))
+		for _, v := range x {
+			vs = append(vs, strconv.Itoa(v))
+		}
+		return recordValueJSON{jsonTypeInts, vs}, nil
+
+	case []int64:
+		vs := make([]string, 0, len(x))
+		for _, v := range x {
+			vs = append(vs, strconv.FormatInt(v, 10))
+		}
+		return recordValueJSON{jsonTypeInts, vs}, nil
+
+	case []float64:
+		return recordValueJSON{jsonTypeFloats, x}, nil
+
+	case []time.Time:
+		vs := make([]string, 0, len(x))
+		for _, v := range x {
+			vs = append(vs, v.Format(time.RFC3339Nano))
+		}
+		return recordValueJSON{jsonTypeTimestamps, vs}, nil
+	}
+	return recordValueJSON{}, fmt.Errorf("unsupported value: %T", x)
+}
+
+// UnmarshalJSON implements json.Unmarshaler.  It decodes the encoding produced by
+// MarshalJSON, and also accepts plain JSON values (as record values can't be objects,
+// any object value is taken to be a typed value).  Plain numbers are decoded as int64 if
+// they are integers and float64 otherwise, and arrays are decoded as []string.
+func (r *Record) UnmarshalJSON(b []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	out := make(Record, len(raw))
+	for k, v := range raw {
+		x, err := recordValueFromJSON(v)
+		if err != nil {
+			return fmt.Errorf("sajari: field %q: %v", k, err)
+		}
+		out[k] = x
+	}
+	*r = out
+	return nil
+}
+
+func recordValueFromJSON(b []byte) (interface{}, error) {
+	b = bytes.TrimSpace(b)
+	if len(b) > 0 && b[0] == '{' {
+		var rv struct {
+			Type  string          `json:"type"`
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(b, &rv); err != nil {
+			return nil, err
+		}
+		return typedValueFromJSON(rv.Type, rv.Value)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(b))
+	dec.UseNumber()
+	var x interface{}
+	if err := dec.Decode(&x); err != nil {
+		return nil, err
+	}
+
+	switch x := x.(type) {
+	case string, bool:
+		return x, nil
+
+	case json.Number:
+		if n, err := x.Int64(); err == nil {
+			return n, nil
+		}
+		return x.Float64()
+
+	case []interface{}:
+		vs := make([]string, 0, len(x))
+		for _, v := range x {
+			vs = append(vs, fmt.Sprintf("%v", v))
+		}
+		return vs, nil
+	}
+	return nil, fmt.Errorf("unsupported value: %s", b)
+}
+
+func typedValueFromJSON(typ string, b []byte) (interface{}, error) {
+	switch typ {
+	case jsonTypeString:
+		var s string
+		err := json.Unmarshal(b, &s)
+		return s, err
+
+	case jsonTypeInt:
+		s, err := numberString(b)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.ParseInt(s, 10, 64)
+
+	case jsonTypeUint:
+		s, err := numberString(b)
+		if err != nil {
+			return nil, err
+		}
+		return strconv.ParseUint(s, 10, 64)
+
+	case jsonTypeFloat:
+		var f float64
+		err := json.Unmarshal(b, &f)
+		return f, err
+
+	case jsonTypeBool:
+		var v bool
+		err := json.Unmarshal(b, &v)
+		return v, err
+
+	case jsonTypeTimestamp:
+		var s string
+		if err := json.Unmarshal(b, &s); err != nil {
+			return nil, err
+		}
+		return time.Parse(time.RFC3339Nano, s)
+
+	case jsonTypeStrings:
+		var vs []string
+		err := json.Unmarshal(b, &vs)
+		return vs, err
+
+	case jsonTypeInts:
+		var ss []json.Number
+		if err := json.Unmarshal(b, &ss); err != nil {
+			return nil, err
+		}
+		vs := make([]int64, 0, len(ss))
+		for _, s := range ss {
+			v, err := strconv.ParseInt(string(s), 10, 64)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+		return vs, nil
+
+	case jsonTypeFloats:
+		var vs []float64
+		err := json.Unmarshal(b, &vs)
+		return vs, err
+
+	case jsonTypeTimestamps:
+		var ss []string
+		if err := json.Unmarshal(b, &ss); err != nil {
+			return nil, err
+		}
+		vs := make([]time.Time, 0, len(ss))
+		for _, s := range ss {
+			v, err := time.Parse(time.RFC3339Nano, s)
+			if err != nil {
+				return nil, err
+			}
+			vs = append(vs, v)
+		}
+		return vs, nil
+	}
+	return nil, fmt.Errorf("unknown type %q", typ)
+}
+
+// numberString returns the digits of b, which is either a JSON number or a string
+// containing a number.
+func numberString(b []byte) (string, error) {
+	var n json.Number
+	if err := json.Unmarshal(b, &n); err != nil {
+		return "", err
+	}
+	return string(n), nil
+}
@@ -0,0 +1,76 @@
+package sajari
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// RequestIDKey is the metadata key used to send request IDs (see WithRequestIDs).
+const RequestIDKey = "x-request-id"
+
+// WithRequestIDs configures the client to send a unique ID with each RPC, so that client
+// logs, server logs and user reports can be matched up.  The ID is sent as metadata with
+// key RequestIDKey, reported in CallInfo.RequestID (see WithStatsHandler) and included in
+// the message of errors returned by the RPC.
+//
+// Use WithRequestID to send a known ID (e.g. the ID of the incoming request being served)
+// instead of a generated one.
+func WithRequestIDs() Opt {
+	return func(c *Client) {
+		c.requestIDs = true
+	}
+}
+
+// WithRequestID returns a context which causes RPCs made with it to send the request ID id.
+// If request IDs are enabled (see WithRequestIDs) then id is used instead of a generated ID.
+func WithRequestID(ctx context.Context, id string) context.Context {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok {
+		md = make(metadata.MD, 1)
+	} else {
+		md = md.Copy()
+	}
+	md[RequestIDKey] = []string{id}
+	return metadata.NewOutgoingContext(ctx, md)
+}
+
+// requestID returns the request ID in the outgoing metadata of ctx, or the empty string
+// if there isn't one.
+func requestID(ctx context.Context) string {
+	md, ok := metadata.FromOutgoingContext(ctx)
+	if !ok || len(md[RequestIDKey]) == 0 {
+		return ""
+	}
+	return md[RequestIDKey][0]
+}
+
+// newRequestID returns a random 128-bit ID encoded as hex.
+func newRequestID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return ""
+	}
+	return hex.EncodeToString(b)
+}
+
+// requestIDInterceptor attaches a request ID to each RPC, and adds it to the message of
+// returned errors.
+func requestIDInterceptor(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	id := requestID(ctx)
+	if id == "" {
+		id = newRequestID()
+		ctx = WithRequestID(ctx, id)
+	}
+
+	err := invoker(ctx, method, req, reply, cc, opts...)
+	if err != nil && id != "" {
+		// Recreate the error so that grpc.Code still returns its code.
+		return grpc.Errorf(grpc.Code(err), "%s (request id %s)", grpc.ErrorDesc(err), id)
+	}
+	return err
+}
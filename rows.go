@@ -0,0 +1,204 @@
+package sajari
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+
+	"golang.org/x/net/context"
+)
+
+// DefaultRowsPageSize is the number of results fetched by each search made by Rows when
+// Request.Limit is not set.
+const DefaultRowsPageSize = 100
+
+// Rows is an iterator over the results of a search, in the style of database/sql.Rows:
+//
+//	rows := client.Query().Rows(ctx, &sajari.Request{
+//		Filter: sajari.FieldFilter("type =", "article"),
+//		Fields: []string{"url", "title", "published"},
+//	})
+//	defer rows.Close()
+//	for rows.Next() {
+//		var url, title string
+//		var published time.Time
+//		if err := rows.Scan(&url, &title, &published); err != nil {
+//			// handle err
+//		}
+//	}
+//	if err := rows.Err(); err != nil {
+//		// handle err
+//	}
+//
+// A Request with no query text and no filter iterates over every record in the collection.
+// Rows is not safe for concurrent use.
+type Rows struct {
+	ctx context.Context
+	q   *Query
+	r   Request
+
+	columns []string
+	results *Results
+	i       int
+	done    bool
+	err     error
+}
+
+// Rows returns an iterator over the results of the search r.  Results are fetched in pages
+// of r.Limit results (or DefaultRowsPageSize if r.Limit is not set) starting from r.Offset,
+// until all matching results have been returned.  The search is not run until Next is
+// called, and r is not modified.
+//
+// Columns are taken from r.Fields (using the alias of entries of the form "field AS alias"),
+// or from the sorted value keys of the first result if r.Fields is empty.
+func (q *Query) Rows(ctx context.Context, r *Request) *Rows {
+	rc := r.Clone()
+	if rc.Limit <= 0 {
+		rc.Limit = DefaultRowsPageSize
+	}
+	rc.SkipResults = false
+
+	columns, err := fieldKeys(rc.Fields)
+	return &Rows{
+		ctx:     ctx,
+		q:       q,
+		r:       rc,
+		columns: columns,
+		i:       -1,
+		err:     err,
+	}
+}
+
+// Next advances to the next result, fetching the next page of results if necessary.  It
+// returns false when there are no more results or an error occurred (see Err).
+func (rs *Rows) Next() bool {
+	if rs.err != nil {
+		return false
+	}
+
+	rs.i++
+	if rs.results != nil && rs.i < len(rs.results.Results) {
+		return true
+	}
+	if rs.done {
+		return false
+	}
+
+	if rs.results != nil {
+		rs.r.Offset += len(rs.results.Results)
+	}
+	results, err := rs.q.Search(rs.ctx, &rs.r)
+	if err != nil {
+		rs.err = err
+		return false
+	}
+	rs.results = results
+	rs.i = 0
+
+	n := len(results.Results)
+	if n < rs.r.Limit || rs.r.Offset+n >= results.TotalResults {
+		rs.done = true
+	}
+	if n == 0 {
+		return false
+	}
+
+	if len(rs.columns) == 0 {
+		for k := range results.Results[0].Values {
+			rs.columns = append(rs.columns, k)
+		}
+		sort.Strings(rs.columns)
+	}
+	return true
+}
+
+// Columns returns the names of the values returned by Scan.  If no fields were requested
+// then Columns returns nil until the first call to Next.
+func (rs *Rows) Columns() []string {
+	return rs.columns
+}
+
+// Result returns the current result, which includes its score and tokens.
+func (rs *Rows) Result() Result {
+	if rs.results == nil || rs.i < 0 || rs.i >= len(rs.results.Results) {
+		return Result{}
+	}
+	return rs.results.Results[rs.i]
+}
+
+// TotalResults returns the total number of results matching the search, as reported by the
+// most recent page of results.
+func (rs *Rows) TotalResults() int {
+	if rs.results == nil {
+		return 0
+	}
+	return rs.results.TotalResults
+}
+
+// Scan copies the values of the current result into dest, which must have one element for
+// each column (see Columns).  Each element of dest must be a pointer to a string, bool,
+// numeric type, time.Time, slice of these or interface{}.  Missing values set dest to its
+// zero value.
+func (rs *Rows) Scan(dest ...interface{}) error {
+	if rs.err != nil {
+		return rs.err
+	}
+	if rs.results == nil || rs.i < 0 || rs.i >= len(rs.results.Results) {
+		return errors.New("sajari: Scan called without calling Next")
+	}
+	if len(dest) != len(rs.columns) {
+		return fmt.Errorf("sajari: expected %d destination arguments in Scan, not %d", len(rs.columns), len(dest))
+	}
+
+	values := rs.results.Results[rs.i].Values
+	for i, d := range dest {
+		if err := scanValue(d, values[rs.columns[i]]); err != nil {
+			return fmt.Errorf("sajari: column %q: %v", rs.columns[i], err)
+		}
+	}
+	return nil
+}
+
+// Err returns the error, if any, encountered while fetching results.
+func (rs *Rows) Err() error {
+	return rs.err
+}
+
+// Close stops the iteration.  Subsequent calls to Next return false.  Close always
+// returns nil, and exists so that Rows can be used in place of database/sql.Rows.
+func (rs *Rows) Close() error {
+	rs.done = true
+	if rs.results != nil {
+		rs.i = len(rs.results.Results)
+	}
+	return nil
+}
+
+// scanValue sets the value pointed to by dst to the result value v.
+func scanValue(dst interface{}, v interface{}) error {
+	dv := reflect.ValueOf(dst)
+	if dv.Kind() != reflect.Ptr || dv.IsNil() {
+		return fmt.Errorf("destination must be a non-nil pointer, got %T", dst)
+	}
+	dv = dv.Elem()
+
+	switch v := v.(type) {
+	case nil:
+		dv.Set(reflect.Zero(dv.Type()))
+		return nil
+
+	case string:
+		return decodeSingle(dv, v)
+
+	case []string:
+		return decodeRepeated(dv, v)
+	}
+
+	rv := reflect.ValueOf(v)
+	if !rv.Type().AssignableTo(dv.Type()) {
+		return fmt.Errorf("cannot scan %T into %v", v, dv.Type())
+	}
+	dv.Set(rv)
+	return nil
+}
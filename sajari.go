@@ -2,12 +2,16 @@
 package sajari // import "code.sajari.com/sajari-sdk-go"
 
 import (
+	"time"
+
 	"golang.org/x/net/context"
 
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/credentials"
 
 	"code.sajari.com/sajari-sdk-go/internal"
+
+	pb "code.sajari.com/protogen-go/sajari/api/query/v1"
 )
 
 const (
@@ -20,11 +24,11 @@ func New(project, collection string, opts ...Opt) (*Client, error) {
 	c := &Client{
 		Project:    project,
 		Collection: collection,
+		profiles:   &profiles{},
 	}
 
 	defaultOpts := []Opt{
 		WithEndpoint(endpoint),
-		WithGRPCDialOption(grpc.WithUserAgent(userAgent)),
 		WithGRPCDialOption(grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(nil, "api.sajari.com"))),
 	}
 
@@ -33,31 +37,152 @@ func New(project, collection string, opts ...Opt) (*Client, error) {
 		opt(c)
 	}
 
+	// A single deadline covers both dialing and the warm-up request.
+	ctx := context.Background()
+	if c.warmUp && c.warmUpTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.warmUpTimeout)
+		defer cancel()
+	}
+
 	if c.ClientConn == nil {
-		conn, err := grpc.Dial(c.endpoint, c.dialOpts...)
+		ua := userAgent
+		if c.userAgentSuffix != "" {
+			ua += " " + c.userAgentSuffix
+		}
+		// Prepend so that a user agent set using WithGRPCDialOption takes precedence.
+		c.dialOpts = append([]grpc.DialOption{grpc.WithUserAgent(ua)}, c.dialOpts...)
+
+		if c.requestIDs {
+			// Outermost, so that other interceptors see the request ID.
+			c.interceptors = append([]grpc.UnaryClientInterceptor{requestIDInterceptor}, c.interceptors...)
+		}
+		if len(c.interceptors) > 0 {
+			c.dialOpts = append(c.dialOpts, grpc.WithUnaryInterceptor(chainInterceptors(c.interceptors)))
+		}
+
+		if c.warmUp {
+			c.dialOpts = append(c.dialOpts, grpc.WithBlock())
+		}
+
+		conn, err := grpc.DialContext(ctx, c.endpoint, c.dialOpts...)
 		if err != nil {
 			return nil, err
 		}
 		c.ClientConn = conn
 	}
+
+	if c.warmUp {
+		if err := c.warm(ctx); err != nil {
+			c.Close()
+			return nil, err
+		}
+	}
 	return c, nil
 }
 
+// warm makes an authenticated request so that subsequent requests can reuse the
+// established connection, and fetches the schema if schema prefetch is enabled.
+func (c *Client) warm(ctx context.Context) error {
+	if c.warmUpSchema {
+		_, err := c.Schema().Refresh(ctx)
+		return err
+	}
+
+	// A minimal search bypassing hooks, caches and hedging: it only needs query
+	// permission on the collection.
+	pr, err := Request{}.proto()
+	if err != nil {
+		return err
+	}
+	skipResults(pr)
+	_, err = pb.NewQueryClient(c.ClientConn).Search(c.newContext(ctx), pr)
+	return err
+}
+
 func (c *Client) newContext(ctx context.Context) context.Context {
 	return internal.NewContext(ctx, c.Project, c.Collection)
 }
 
 // Client is a type which makes requests to the Sajari Engine.
+//
+// A Client is safe for concurrent use by multiple goroutines, as are the handles created
+// from it (Query, Schema and Pipeline) and its caches.  The exported Project and Collection
+// fields must not be changed once the Client is in use: use For to make requests to
+// another collection.
 type Client struct {
 	Project    string
 	Collection string
 	endpoint   string
 
-	ClientConn *grpc.ClientConn
-	dialOpts   []grpc.DialOption
+	ClientConn   *grpc.ClientConn
+	dialOpts     []grpc.DialOption
+	interceptors []grpc.UnaryClientInterceptor
+	sharedConn   bool // ClientConn is owned by another Client (see For).
+	requestIDs   bool
+
+	userAgentSuffix string
+
+	schemaCache *schemaCache
+	queryCache  *queryCache
+	searchGroup *searchGroup
+
+	warmUp        bool
+	warmUpSchema  bool
+	warmUpTimeout time.Duration
+
+	borrowValues bool
+
+	defaultAddTransforms []Transform
+	validateTransforms   bool
+
+	excludeArchivedRecords bool
+	timestampValues        bool
+
+	archivedField string
+
+	hedgeDelay    time.Duration
+	hedgeAttempts int
+
+	profiles *profiles
+
+	queryHooks    []QueryHook
+	pipelineHooks []PipelineHook
+	resultHooks   []ResultHook
 }
 
-// Close releases all resources held by the Client.
+// Close releases all resources held by the Client.  Closing a Client created by For
+// does nothing: close the Client it was created from instead.
 func (c *Client) Close() error {
+	if c.sharedConn {
+		return nil
+	}
 	return c.ClientConn.Close()
 }
+
+// For returns a Client for the collection in project which shares the connection,
+// credentials and options of c.  This is much cheaper than calling New, and is intended
+// for services which make requests to many collections (e.g. one per tenant).
+//
+// Caches (see WithSchemaCache and WithQueryCache) are not shared: the returned Client has
+// its own empty caches with the same configuration as c.  The returned Client is only valid
+// until c is closed.
+func (c *Client) For(project, collection string) *Client {
+	cc := *c
+	cc.Project = project
+	cc.Collection = collection
+	cc.sharedConn = true
+
+	if c.schemaCache != nil {
+		cc.schemaCache = &schemaCache{
+			ttl: c.schemaCache.ttl,
+		}
+	}
+	if c.queryCache != nil {
+		WithQueryCache(c.queryCache.size, c.queryCache.ttl)(&cc)
+	}
+	if c.searchGroup != nil {
+		cc.searchGroup = &searchGroup{}
+	}
+	return &cc
+}
@@ -0,0 +1,155 @@
+package sajaritest
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+	"time"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+var words = []string{
+	"alpha", "bravo", "charlie", "delta", "echo", "foxtrot", "golf", "hotel",
+	"india", "juliet", "kilo", "lima", "mike", "november", "oscar", "papa",
+	"quebec", "romeo", "sierra", "tango", "uniform", "victor", "whiskey",
+	"xray", "yankee", "zulu", "search", "engine", "record", "collection",
+	"query", "result", "filter", "boost", "score", "field", "index", "term",
+}
+
+// baseTime is the earliest generated timestamp.
+var baseTime = time.Date(2017, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+// Records generates n records which conform to schema.  Records are generated
+// from seed, so the same arguments always produce the same records.
+//
+// Required fields are always set, other fields are set on most records.  Values
+// of unique fields are distinct across the generated records, and values of indexed
+// fields are short runs of text.  Unique boolean fields can't have distinct values
+// across more than two records, so schemas containing them are rejected.
+func Records(schema []sajari.Field, n int, seed int64) ([]sajari.Record, error) {
+	for _, f := range schema {
+		if f.Unique && f.Type == sajari.TypeBoolean {
+			return nil, fmt.Errorf("sajaritest: cannot generate values for unique boolean field %q", f.Name)
+		}
+	}
+
+	g := &generator{
+		r: rand.New(rand.NewSource(seed)),
+	}
+
+	out := make([]sajari.Record, 0, n)
+	for i := 0; i < n; i++ {
+		rec := make(sajari.Record, len(schema))
+		for _, f := range schema {
+			if !f.Required && !f.Unique && g.r.Intn(10) == 0 {
+				continue
+			}
+			rec[f.Name] = g.value(f, i)
+		}
+		out = append(out, rec)
+	}
+	return out, nil
+}
+
+type generator struct {
+	r *rand.Rand
+}
+
+func (g *generator) value(f sajari.Field, i int) interface{} {
+	if !f.Repeated {
+		if f.Unique {
+			return g.unique(f, i)
+		}
+		return g.single(f)
+	}
+
+	// Repeated integer and timestamp fields are []int64, others are []string.
+	n := 1
+	if !f.Unique {
+		n += g.r.Intn(4)
+	}
+	if f.Type == sajari.TypeInteger || f.Type == sajari.TypeTimestamp {
+		vs := make([]int64, 0, n)
+		for j := 0; j < n; j++ {
+			if f.Unique {
+				vs = append(vs, g.uniqueInt64(f.Type, i))
+				continue
+			}
+			vs = append(vs, g.int64(f.Type))
+		}
+		return vs
+	}
+
+	vs := make([]string, 0, n)
+	for j := 0; j < n; j++ {
+		if f.Unique {
+			vs = append(vs, fmt.Sprintf("%v", g.unique(f, i)))
+			continue
+		}
+		vs = append(vs, fmt.Sprintf("%v", g.single(f)))
+	}
+	return vs
+}
+
+// unique returns a single value for the ith record which is distinct from the
+// values generated for all other records.  f must not be a boolean field.
+func (g *generator) unique(f sajari.Field, i int) interface{} {
+	switch f.Type {
+	case sajari.TypeInteger:
+		return g.uniqueInt64(f.Type, i)
+
+	case sajari.TypeFloat:
+		return float64(i + 1)
+
+	case sajari.TypeTimestamp:
+		return time.Unix(g.uniqueInt64(f.Type, i), 0)
+	}
+	return f.Name + "-" + strconv.Itoa(i+1)
+}
+
+// uniqueInt64 returns the distinct integer or timestamp (as seconds since the Unix
+// epoch) for the ith record.
+func (g *generator) uniqueInt64(t sajari.Type, i int) int64 {
+	if t == sajari.TypeTimestamp {
+		return baseTime.Unix() + int64(i)
+	}
+	return int64(i + 1)
+}
+
+func (g *generator) single(f sajari.Field) interface{} {
+	switch f.Type {
+	case sajari.TypeInteger:
+		return g.int64(f.Type)
+
+	case sajari.TypeFloat:
+		return float64(g.r.Intn(100000)) / 100
+
+	case sajari.TypeBoolean:
+		return g.r.Intn(2) == 1
+
+	case sajari.TypeTimestamp:
+		return time.Unix(g.int64(f.Type), 0)
+	}
+
+	if f.Indexed || f.Name == sajari.BodyField {
+		return g.text(5 + g.r.Intn(20))
+	}
+	return g.text(1)
+}
+
+func (g *generator) int64(t sajari.Type) int64 {
+	if t == sajari.TypeTimestamp {
+		return baseTime.Unix() + g.r.Int63n(365*24*60*60)
+	}
+	return g.r.Int63n(1000)
+}
+
+func (g *generator) text(n int) string {
+	ws := make([]string, 0, n)
+	for i := 0; i < n; i++ {
+		ws = append(ws, words[g.r.Intn(len(words))])
+	}
+	return strings.Join(ws, " ")
+}
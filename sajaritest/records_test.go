@@ -0,0 +1,110 @@
+package sajaritest
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+var testSchema = []sajari.Field{
+	{Name: "id", Type: sajari.TypeString, Unique: true, Required: true},
+	{Name: "title", Type: sajari.TypeString, Indexed: true},
+	{Name: "count", Type: sajari.TypeInteger, Unique: true},
+	{Name: "price", Type: sajari.TypeFloat},
+	{Name: "published", Type: sajari.TypeTimestamp, Unique: true},
+	{Name: "active", Type: sajari.TypeBoolean, Required: true},
+	{Name: "tags", Type: sajari.TypeString, Repeated: true, Unique: true},
+	{Name: "ids", Type: sajari.TypeInteger, Repeated: true, Unique: true},
+	{Name: "sizes", Type: sajari.TypeInteger, Repeated: true},
+}
+
+func TestRecordsDeterministic(t *testing.T) {
+	a, err := Records(testSchema, 50, 1)
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+	b, err := Records(testSchema, 50, 1)
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+	if !reflect.DeepEqual(a, b) {
+		t.Errorf("Records() with the same seed produced different records")
+	}
+
+	c, err := Records(testSchema, 50, 2)
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+	if reflect.DeepEqual(a, c) {
+		t.Errorf("Records() with different seeds produced the same records")
+	}
+}
+
+func TestRecordsConform(t *testing.T) {
+	rs, err := Records(testSchema, 50, 1)
+	if err != nil {
+		t.Fatalf("Records() error: %v", err)
+	}
+
+	seen := make(map[string]map[interface{}]bool)
+	for i, r := range rs {
+		for _, f := range testSchema {
+			v, ok := r[f.Name]
+			if !ok {
+				if f.Required || f.Unique {
+					t.Errorf("record %d: %q not set", i, f.Name)
+				}
+				continue
+			}
+
+			var want interface{}
+			switch {
+			case f.Repeated && (f.Type == sajari.TypeInteger || f.Type == sajari.TypeTimestamp):
+				want = []int64(nil)
+			case f.Repeated:
+				want = []string(nil)
+			case f.Type == sajari.TypeInteger:
+				want = int64(0)
+			case f.Type == sajari.TypeFloat:
+				want = float64(0)
+			case f.Type == sajari.TypeBoolean:
+				want = false
+			case f.Type == sajari.TypeTimestamp:
+				want = time.Time{}
+			default:
+				want = ""
+			}
+			if reflect.TypeOf(v) != reflect.TypeOf(want) {
+				t.Errorf("record %d: %q has type %T, expected %T", i, f.Name, v, want)
+				continue
+			}
+
+			if f.Unique {
+				if seen[f.Name] == nil {
+					seen[f.Name] = make(map[interface{}]bool)
+				}
+				k := v
+				if rv := reflect.ValueOf(v); rv.Kind() == reflect.Slice {
+					if rv.Len() != 1 {
+						t.Errorf("record %d: unique %q has %d values, expected 1", i, f.Name, rv.Len())
+						continue
+					}
+					k = rv.Index(0).Interface()
+				}
+				if seen[f.Name][k] {
+					t.Errorf("record %d: unique %q value %v is repeated", i, f.Name, v)
+				}
+				seen[f.Name][k] = true
+			}
+		}
+	}
+}
+
+func TestRecordsUniqueBoolean(t *testing.T) {
+	schema := []sajari.Field{{Name: "flag", Type: sajari.TypeBoolean, Unique: true}}
+	if _, err := Records(schema, 10, 1); err == nil {
+		t.Errorf("Records() with a unique boolean field: expected error")
+	}
+}
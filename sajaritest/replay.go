@@ -0,0 +1,203 @@
+// Package sajaritest provides utilities for testing applications which use the Sajari SDK.
+package sajaritest // import "code.sajari.com/sajari-sdk-go/sajaritest"
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"sync"
+
+	"github.com/golang/protobuf/jsonpb"
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	"code.sajari.com/sajari-sdk-go"
+)
+
+// Mode determines whether a Recorder records or replays calls.
+type Mode int
+
+// Mode constants.
+const (
+	// ModeRecord passes calls through to the server, and records requests
+	// and responses.
+	ModeRecord Mode = iota
+
+	// ModeReplay answers calls with previously recorded responses, without
+	// contacting the server.
+	ModeReplay
+)
+
+// call is a recorded RPC.
+type call struct {
+	Method   string          `json:"method"`
+	Request  json.RawMessage `json:"request"`
+	Response json.RawMessage `json:"response,omitempty"`
+	Code     codes.Code      `json:"code"`
+	Message  string          `json:"message,omitempty"`
+}
+
+// Recorder records calls made by a sajari.Client to a golden file, and replays them
+// so that tests can be run without network access.
+//
+//	r, err := sajaritest.NewRecorder("testdata/search.json", sajaritest.ModeReplay)
+//	if err != nil {
+//	    // ...
+//	}
+//	defer r.Close()
+//
+//	client, err := sajari.New("project", "collection", r.Opt())
+//
+// In replay mode, each call is matched to a recorded call with the same method and
+// request.  Identical calls are replayed in the order in which they were recorded.
+type Recorder struct {
+	path string
+	mode Mode
+
+	mu     sync.Mutex
+	calls  []call
+	replay map[string][]call
+}
+
+var marshaler = jsonpb.Marshaler{}
+
+// NewRecorder creates a new Recorder which reads or writes calls to the file at path.  In
+// replay mode the file is read immediately.  In record mode the file is written by Close.
+func NewRecorder(path string, mode Mode) (*Recorder, error) {
+	r := &Recorder{
+		path: path,
+		mode: mode,
+	}
+
+	if mode == ModeReplay {
+		b, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, err
+		}
+
+		var calls []call
+		if err := json.Unmarshal(b, &calls); err != nil {
+			return nil, fmt.Errorf("sajaritest: error reading %v: %v", path, err)
+		}
+
+		r.replay = make(map[string][]call, len(calls))
+		for _, c := range calls {
+			k, err := callKey(c.Method, c.Request)
+			if err != nil {
+				return nil, fmt.Errorf("sajaritest: error reading %v: %v", path, err)
+			}
+			r.replay[k] = append(r.replay[k], c)
+		}
+	}
+	return r, nil
+}
+
+// Opt returns a sajari.Opt which configures a Client to use the Recorder.
+func (r *Recorder) Opt() sajari.Opt {
+	return sajari.WithUnaryInterceptor(r.intercept)
+}
+
+// Close writes recorded calls to the golden file.  In replay mode Close does nothing.
+func (r *Recorder) Close() error {
+	if r.mode != ModeRecord {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	b, err := json.MarshalIndent(r.calls, "", "  ")
+	if err != nil {
+		return err
+	}
+	return ioutil.WriteFile(r.path, b, 0644)
+}
+
+func (r *Recorder) intercept(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+	reqJSON, err := marshalMessage(req)
+	if err != nil {
+		return err
+	}
+
+	if r.mode == ModeReplay {
+		return r.replayCall(method, reqJSON, reply)
+	}
+
+	err = invoker(ctx, method, req, reply, cc, opts...)
+
+	c := call{
+		Method:  method,
+		Request: reqJSON,
+		Code:    grpc.Code(err),
+		Message: grpc.ErrorDesc(err),
+	}
+	if err == nil {
+		c.Message = ""
+		resp, merr := marshalMessage(reply)
+		if merr != nil {
+			return merr
+		}
+		c.Response = resp
+	}
+
+	r.mu.Lock()
+	r.calls = append(r.calls, c)
+	r.mu.Unlock()
+	return err
+}
+
+func (r *Recorder) replayCall(method string, reqJSON []byte, reply interface{}) error {
+	k, err := callKey(method, reqJSON)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	cs := r.replay[k]
+	if len(cs) == 0 {
+		r.mu.Unlock()
+		return grpc.Errorf(codes.Unavailable, "sajaritest: no recorded call for %v with request %s", method, reqJSON)
+	}
+	c := cs[0]
+	if len(cs) > 1 {
+		// Keep the last call around so that it can be replayed repeatedly.
+		r.replay[k] = cs[1:]
+	}
+	r.mu.Unlock()
+
+	if c.Code != codes.OK {
+		return grpc.Errorf(c.Code, "%s", c.Message)
+	}
+
+	m, ok := reply.(proto.Message)
+	if !ok {
+		return fmt.Errorf("sajaritest: unexpected reply type %T", reply)
+	}
+	return jsonpb.Unmarshal(bytes.NewReader(c.Response), m)
+}
+
+func marshalMessage(m interface{}) (json.RawMessage, error) {
+	pm, ok := m.(proto.Message)
+	if !ok {
+		return nil, fmt.Errorf("sajaritest: unexpected message type %T", m)
+	}
+	s, err := marshaler.MarshalToString(pm)
+	if err != nil {
+		return nil, err
+	}
+	return json.RawMessage(s), nil
+}
+
+// callKey returns a key for matching calls.  Requests are compacted so that golden files
+// can be reformatted by hand.
+func callKey(method string, req json.RawMessage) (string, error) {
+	var buf bytes.Buffer
+	if err := json.Compact(&buf, req); err != nil {
+		return "", err
+	}
+	return method + " " + buf.String(), nil
+}
@@ -0,0 +1,85 @@
+package sajaritest
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+
+	enginepb "code.sajari.com/protogen-go/sajari/engine"
+)
+
+func single(s string) *enginepb.Value {
+	return &enginepb.Value{
+		Value: &enginepb.Value_Single{
+			Single: s,
+		},
+	}
+}
+
+func TestRecorderReplay(t *testing.T) {
+	dir, err := ioutil.TempDir("", "sajaritest")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "calls.json")
+
+	const errMsg = "100% of values are invalid: %v"
+
+	rec, err := NewRecorder(path, ModeRecord)
+	if err != nil {
+		t.Fatalf("NewRecorder(ModeRecord) error: %v", err)
+	}
+	invoker := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		if method == "/fail" {
+			return grpc.Errorf(codes.InvalidArgument, "%s", errMsg)
+		}
+		proto.Merge(reply.(proto.Message), single("reply"))
+		return nil
+	}
+
+	reply := &enginepb.Value{}
+	if err := rec.intercept(context.Background(), "/ok", single("request"), reply, nil, invoker); err != nil {
+		t.Fatalf("recording /ok: %v", err)
+	}
+	if err := rec.intercept(context.Background(), "/fail", single("request"), &enginepb.Value{}, nil, invoker); err == nil {
+		t.Fatalf("recording /fail: expected error")
+	}
+	if err := rec.Close(); err != nil {
+		t.Fatalf("Close() error: %v", err)
+	}
+
+	rep, err := NewRecorder(path, ModeReplay)
+	if err != nil {
+		t.Fatalf("NewRecorder(ModeReplay) error: %v", err)
+	}
+	noCall := func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		t.Errorf("unexpected call to %v in replay mode", method)
+		return nil
+	}
+
+	reply = &enginepb.Value{}
+	if err := rep.intercept(context.Background(), "/ok", single("request"), reply, nil, noCall); err != nil {
+		t.Fatalf("replaying /ok: %v", err)
+	}
+	if !proto.Equal(reply, single("reply")) {
+		t.Errorf("replayed /ok reply = %v, expected %v", reply, single("reply"))
+	}
+
+	err = rep.intercept(context.Background(), "/fail", single("request"), &enginepb.Value{}, nil, noCall)
+	if grpc.Code(err) != codes.InvalidArgument || grpc.ErrorDesc(err) != errMsg {
+		t.Errorf("replayed /fail error = %v (code %v), expected %q (code %v)", grpc.ErrorDesc(err), grpc.Code(err), errMsg, codes.InvalidArgument)
+	}
+
+	err = rep.intercept(context.Background(), "/ok", single("other"), &enginepb.Value{}, nil, noCall)
+	if grpc.Code(err) != codes.Unavailable {
+		t.Errorf("replaying unrecorded request: code %v, expected %v", grpc.Code(err), codes.Unavailable)
+	}
+}
@@ -20,7 +20,7 @@ func (c *Client) Schema() *Schema {
 }
 
 // Schema provides methods for managing collection schemas.  Use Client.Schema to create
-// one for a collection.
+// one for a collection.  It is safe for concurrent use.
 type Schema struct {
 	c *Client
 }
@@ -196,6 +196,7 @@ func (s *Schema) Add(ctx context.Context, fs ...Field) error {
 	if err != nil {
 		return err
 	}
+	s.Invalidate()
 	return multiErrorFromSchemaStatusProto(resp.Status)
 }
 
@@ -207,13 +208,14 @@ func (s *Schema) MutateField(ctx context.Context, name string, muts ...Mutation)
 		return err
 	}
 
-	resp, err := pb.NewSchemaClient(s.c.ClientConn).MutateField(ctx, &pb.MutateFieldRequest{
+	resp, err := pb.NewSchemaClient(s.c.ClientConn).MutateField(s.c.newContext(ctx), &pb.MutateFieldRequest{
 		Name:      name,
 		Mutations: pbMuts,
 	})
 	if err != nil {
 		return err
 	}
+	s.Invalidate()
 	return multiErrorFromSchemaStatusProto(resp.Status)
 }
 
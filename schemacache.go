@@ -0,0 +1,96 @@
+package sajari
+
+import (
+	"sync"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// WithSchemaCache configures the client to cache the collection schema for ttl.  If ttl
+// is zero then the cached schema does not expire and is only refreshed when invalidated
+// (see Schema.Invalidate).
+//
+// The cache is used by Schema.CachedFields, and is invalidated automatically when the
+// schema is changed using Schema.Add or Schema.MutateField.
+func WithSchemaCache(ttl time.Duration) Opt {
+	return func(c *Client) {
+		c.schemaCache = &schemaCache{
+			ttl: ttl,
+		}
+	}
+}
+
+// schemaCache is a cache of collection fields.
+type schemaCache struct {
+	ttl time.Duration
+
+	mu      sync.Mutex
+	fields  []Field
+	fetched time.Time
+}
+
+// get returns the cached fields, or nil if the cache is empty or has expired.
+func (sc *schemaCache) get() []Field {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+
+	if sc.fields == nil {
+		return nil
+	}
+	if sc.ttl > 0 && time.Since(sc.fetched) > sc.ttl {
+		return nil
+	}
+	return sc.fields
+}
+
+func (sc *schemaCache) set(fs []Field) {
+	sc.mu.Lock()
+	sc.fields = fs
+	sc.fetched = time.Now()
+	sc.mu.Unlock()
+}
+
+func (sc *schemaCache) invalidate() {
+	sc.mu.Lock()
+	sc.fields = nil
+	sc.mu.Unlock()
+}
+
+// CachedFields returns the fields in the collection, using the schema cache if it has been
+// enabled on the Client (see WithSchemaCache).  If the cache is empty or has expired then the
+// fields are fetched and the cache is updated.  If the cache is not enabled then this is
+// equivalent to Fields.
+//
+// The returned slice is shared and must not be modified.
+func (s *Schema) CachedFields(ctx context.Context) ([]Field, error) {
+	if s.c.schemaCache == nil {
+		return s.Fields(ctx)
+	}
+
+	if fs := s.c.schemaCache.get(); fs != nil {
+		return fs, nil
+	}
+	return s.Refresh(ctx)
+}
+
+// Refresh fetches the fields in the collection and updates the schema cache (if enabled).
+// Use this to prime the cache at startup.
+func (s *Schema) Refresh(ctx context.Context) ([]Field, error) {
+	fs, err := s.Fields(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if s.c.schemaCache != nil {
+		s.c.schemaCache.set(fs)
+	}
+	return fs, nil
+}
+
+// Invalidate clears the schema cache (if enabled) so that the next call to CachedFields
+// fetches the schema.
+func (s *Schema) Invalidate() {
+	if s.c.schemaCache != nil {
+		s.c.schemaCache.invalidate()
+	}
+}
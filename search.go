@@ -2,8 +2,10 @@ package sajari
 
 import (
 	"fmt"
+	"math"
 	"strings"
 	"time"
+	"unicode"
 
 	pb "code.sajari.com/protogen-go/sajari/api/query/v1"
 	querypb "code.sajari.com/protogen-go/sajari/engine/query/v1"
@@ -34,7 +36,8 @@ func (t TrackingType) proto() (pb.SearchRequest_Tracking_Type, error) {
 	return pb.SearchRequest_Tracking_NONE, fmt.Errorf("unknown TrackingType: %v", t)
 }
 
-// Tracking configures tracking for a search query.
+// Tracking configures tracking for a search query.  Use ClickTracking or PosNegTracking
+// to construct a validated Tracking.
 type Tracking struct {
 	// Tracking specifies which kind (if any) tokens should be generated and returned
 	// with the query results.
@@ -58,6 +61,77 @@ type Tracking struct {
 	Data map[string]string
 }
 
+// ClickTracking returns a Tracking which generates click tokens for results, identified
+// by the value of field.  The field must be set, and should be a unique field which is
+// returned with results (see Tracking.Validate).  Data is recorded along with the
+// tracking data produced for the request, and can be nil.
+func ClickTracking(field string, data map[string]string) (Tracking, error) {
+	return newTracking(TrackingClick, field, data)
+}
+
+// PosNegTracking returns a Tracking which generates positive/negative interaction tokens
+// for results, identified by the value of field.  The field must be set, and should be a
+// unique field which is returned with results (see Tracking.Validate).  Data is recorded
+// along with the tracking data produced for the request, and can be nil.
+func PosNegTracking(field string, data map[string]string) (Tracking, error) {
+	return newTracking(TrackingPosNeg, field, data)
+}
+
+func newTracking(typ TrackingType, field string, data map[string]string) (Tracking, error) {
+	t := Tracking{
+		Type:  typ,
+		Field: field,
+		Data:  data,
+	}
+	if err := t.check(); err != nil {
+		return Tracking{}, err
+	}
+	return t, nil
+}
+
+// check validates the Tracking without reference to the collection schema.
+func (t Tracking) check() error {
+	if _, err := t.Type.proto(); err != nil {
+		return err
+	}
+	if t.Type == TrackingNone {
+		return nil
+	}
+	if t.Field == "" {
+		return fmt.Errorf("tracking type %v requires a field", t.Type)
+	}
+	if strings.TrimSpace(t.Field) != t.Field {
+		return fmt.Errorf("tracking field %q has leading or trailing whitespace", t.Field)
+	}
+	return nil
+}
+
+// Validate checks that the Tracking is valid for a collection with the schema fs
+// (see Schema.Fields and Schema.CachedFields).  The tracking field must exist in the
+// schema, be unique and must not be repeated.
+func (t Tracking) Validate(fs []Field) error {
+	if err := t.check(); err != nil {
+		return err
+	}
+	if t.Type == TrackingNone {
+		return nil
+	}
+
+	for _, f := range fs {
+		if f.Name != t.Field {
+			continue
+		}
+		if !f.Unique {
+			return fmt.Errorf("tracking field %q is not unique", t.Field)
+		}
+		if f.Repeated {
+			return fmt.Errorf("tracking field %q is repeated", t.Field)
+		}
+		return nil
+	}
+	return fmt.Errorf("tracking field %q is not in the schema", t.Field)
+}
+
 func (t Tracking) proto() (*pb.SearchRequest_Tracking, error) {
 	pbType, err := t.Type.proto()
 	if err != nil {
@@ -82,7 +156,8 @@ type IndexQuery struct {
 	// Body is a list of weighted free-text.
 	Body []Body
 
-	// Terms is a list of pre-split terms.
+	// Terms is a list of pre-split terms, used alongside Text and Body.  Use
+	// TermsQuery to construct an IndexQuery from terms alone.
 	Terms []Term
 
 	// FieldBoosts to be applied to the index score.
@@ -170,7 +245,10 @@ type Request struct {
 	// Ordering applied to results.
 	Sort []Sort
 
-	// Fields returned in results, if empty will return all fields.
+	// Fields returned in results, if empty will return all fields.  Entries of the
+	// form "field AS alias" return the value of field with the key alias in
+	// Result.Values (and are matched against alias when using SearchInto).  Aliases
+	// are not preserved by MarshalRequestJSON.
 	Fields []string
 
 	// Aggregates is a set of Aggregates to run against a result set.
@@ -178,13 +256,36 @@ type Request struct {
 
 	// Transforms is a list of transforms to be applied to the query before it is run.
 	Transforms []Transform
+
+	// SkipResults requests only the aggregates and total number of results, and no
+	// individual results.  Use this to refresh facet counts cheaply.
+	//
+	// The engine always returns at least one result, so the request asks for a single
+	// result with only the _id field (and no sort) and Results.Results is left empty.
+	// SkipResults is not preserved by MarshalRequestJSON.
+	SkipResults bool
+
+	// Profile is the name of a profile registered with Client.RegisterProfile, whose
+	// boosts are added to the request.  If tracking is enabled then the profile name is
+	// recorded in the tracking data (see ProfileTrackingKey).  Profile is not preserved
+	// by MarshalRequestJSON.
+	Profile string
+
+	// IncludeArchived includes archived records in the results, for clients configured
+	// with WithArchivedExcluded (see Client.Archive).
+	IncludeArchived bool
 }
 
 func (r Request) proto() (*pb.SearchRequest, error) {
+	fields, _, err := parseFields(r.Fields)
+	if err != nil {
+		return nil, err
+	}
+
 	req := &querypb.SearchRequest{
 		Offset: int32(r.Offset),
 		Limit:  int32(r.Limit),
-		Fields: r.Fields,
+		Fields: fields,
 	}
 
 	iq, err := r.IndexQuery.proto()
@@ -244,6 +345,16 @@ func (r Request) proto() (*pb.SearchRequest, error) {
 	}, nil
 }
 
+// skipResults rewrites pr, the proto of a Request with SkipResults set, so that the engine
+// fetches as little as possible: the first result with only its ID, and no sorting.
+func skipResults(pr *pb.SearchRequest) {
+	req := pr.SearchRequest
+	req.Offset = 0
+	req.Limit = 1
+	req.Fields = []string{IDField}
+	req.Sort = nil
+}
+
 // Body is weighted free text.
 type Body struct {
 	// Text to search for.
@@ -281,6 +392,41 @@ type Term struct {
 	POff   uint16  // Paragraph offset
 }
 
+// TermsQuery returns an IndexQuery which searches for the pre-analysed terms ts and
+// does not use Text or Body, for callers which do their own query analysis.  Each
+// term is checked with Term.Validate.
+func TermsQuery(ts []Term) (IndexQuery, error) {
+	if len(ts) == 0 {
+		return IndexQuery{}, fmt.Errorf("no terms")
+	}
+	for i, t := range ts {
+		if err := t.Validate(); err != nil {
+			return IndexQuery{}, fmt.Errorf("term %d: %v", i, err)
+		}
+	}
+	return IndexQuery{
+		Terms: ts,
+	}, nil
+}
+
+// Validate checks that the term has a non-empty value without whitespace, a positive
+// (finite) weight, and that its paragraph offset does not exceed its word offset.
+func (t Term) Validate() error {
+	if t.Value == "" {
+		return fmt.Errorf("empty value")
+	}
+	if strings.IndexFunc(t.Value, unicode.IsSpace) >= 0 {
+		return fmt.Errorf("value %q contains whitespace", t.Value)
+	}
+	if math.IsNaN(t.Weight) || math.IsInf(t.Weight, 0) || t.Weight <= 0 {
+		return fmt.Errorf("value %q: invalid weight %v, must be positive", t.Value, t.Weight)
+	}
+	if t.POff > t.WOff {
+		return fmt.Errorf("value %q: paragraph offset %d is greater than word offset %d", t.Value, t.POff, t.WOff)
+	}
+	return nil
+}
+
 func (t Term) proto() *querypb.Term {
 	return &querypb.Term{
 		Value:      t.Value,
@@ -293,22 +439,36 @@ func (t Term) proto() *querypb.Term {
 	}
 }
 
-func processResponse(pbResp *querypb.SearchResponse, tokens []*pb.Token) (*Results, error) {
+// processResponse converts a search response into Results, renaming result values using
+// aliases (which can be nil).  If dec is non-nil then result values are decoded by dec and
+// Result.Values is left unset.
+func (c *Client) processResponse(pbResp *querypb.SearchResponse, tokens []*pb.Token, aliases fieldAliases, dec *resultDecoder) (*Results, error) {
+	if dec != nil {
+		dec.reset(len(pbResp.Results))
+	}
+
 	results := make([]Result, 0, len(pbResp.Results))
 	for i, pbr := range pbResp.Results {
-		values := make(map[string]interface{}, len(pbr.Values))
-		for k, v := range pbr.Values {
-			vv, err := valueFromProto(v)
-			if err != nil {
-				return nil, err
-			}
-			values[k] = vv
-		}
-
 		r := Result{
 			Score:      pbr.Score,
 			IndexScore: pbr.IndexScore,
-			Values:     values,
+		}
+
+		pbValues := aliases.apply(pbr.Values)
+		if dec != nil {
+			if err := dec.decode(pbValues); err != nil {
+				return nil, err
+			}
+		} else {
+			values := make(map[string]interface{}, len(pbValues))
+			for k, v := range pbValues {
+				vv, err := valueFromProto(v, c.borrowValues)
+				if err != nil {
+					return nil, err
+				}
+				values[k] = vv
+			}
+			r.Values = values
 		}
 
 		if len(tokens) > i {
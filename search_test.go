@@ -0,0 +1,37 @@
+package sajari
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestSkipResults(t *testing.T) {
+	r := &Request{
+		Offset:      20,
+		Limit:       10,
+		Fields:      []string{"title"},
+		Sort:        []Sort{SortByField("-published")},
+		SkipResults: true,
+	}
+
+	// proto is a faithful conversion of the request.
+	pr, err := r.proto()
+	if err != nil {
+		t.Fatalf("proto() error: %v", err)
+	}
+	req := pr.SearchRequest
+	if req.Offset != 20 || req.Limit != 10 || !reflect.DeepEqual(req.Fields, []string{"title"}) || len(req.Sort) != 1 {
+		t.Errorf("proto() = offset %d, limit %d, fields %v, %d sorts, expected the request values", req.Offset, req.Limit, req.Fields, len(req.Sort))
+	}
+
+	// The request which is sent fetches as little as possible.
+	c := &Client{profiles: &profiles{}}
+	_, pr, _, err = c.Query().prepare(r)
+	if err != nil {
+		t.Fatalf("prepare() error: %v", err)
+	}
+	req = pr.SearchRequest
+	if req.Offset != 0 || req.Limit != 1 || !reflect.DeepEqual(req.Fields, []string{IDField}) || req.Sort != nil {
+		t.Errorf("prepare() = offset %d, limit %d, fields %v, sorts %v, expected offset 0, limit 1, fields [%v] and no sorts", req.Offset, req.Limit, req.Fields, req.Sort, IDField)
+	}
+}
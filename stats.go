@@ -0,0 +1,90 @@
+package sajari
+
+import (
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"golang.org/x/net/context"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// CallInfo describes a single RPC made by a Client.  See WithStatsHandler.
+type CallInfo struct {
+	// Method is the full name of the gRPC method which was called.
+	Method string
+
+	// RequestSize is the size of the encoded request message in bytes.
+	RequestSize int
+
+	// ResponseSize is the size of the encoded response message in bytes.
+	// Zero if the call failed.
+	ResponseSize int
+
+	// Duration is the time taken for the call to complete.
+	Duration time.Duration
+
+	// Code is the status code of the call.
+	Code codes.Code
+
+	// Err is the error returned from the call, if any.
+	Err error
+
+	// RequestID is the ID sent with the call, if any (see WithRequestIDs).
+	RequestID string
+}
+
+// WithStatsHandler configures the client to call h after each RPC has completed.  The
+// handler is called synchronously, so should return quickly.
+func WithStatsHandler(h func(CallInfo)) Opt {
+	return func(c *Client) {
+		c.interceptors = append(c.interceptors, statsInterceptor(h))
+	}
+}
+
+func statsInterceptor(h func(CallInfo)) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		start := time.Now()
+		err := invoker(ctx, method, req, reply, cc, opts...)
+
+		ci := CallInfo{
+			Method:      method,
+			RequestSize: messageSize(req),
+			Duration:    time.Since(start),
+			Code:        grpc.Code(err),
+			Err:         err,
+			RequestID:   requestID(ctx),
+		}
+		if err == nil {
+			ci.ResponseSize = messageSize(reply)
+		}
+		h(ci)
+		return err
+	}
+}
+
+func messageSize(m interface{}) int {
+	if pm, ok := m.(proto.Message); ok {
+		return proto.Size(pm)
+	}
+	return 0
+}
+
+// chainInterceptors combines interceptors into a single interceptor.  The first
+// interceptor is the outermost.
+func chainInterceptors(is []grpc.UnaryClientInterceptor) grpc.UnaryClientInterceptor {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, invoker grpc.UnaryInvoker, opts ...grpc.CallOption) error {
+		next := invoker
+		for i := len(is) - 1; i >= 0; i-- {
+			next = wrapInvoker(is[i], next)
+		}
+		return next(ctx, method, req, reply, cc, opts...)
+	}
+}
+
+func wrapInvoker(i grpc.UnaryClientInterceptor, invoker grpc.UnaryInvoker) grpc.UnaryInvoker {
+	return func(ctx context.Context, method string, req, reply interface{}, cc *grpc.ClientConn, opts ...grpc.CallOption) error {
+		return i(ctx, method, req, reply, cc, invoker, opts...)
+	}
+}
@@ -0,0 +1,93 @@
+package sajari
+
+import (
+	"strings"
+	"sync"
+
+	"golang.org/x/net/context"
+
+	acpb "code.sajari.com/protogen-go/sajari/autocomplete"
+)
+
+// SuggestOptions configures Query.Suggest.
+type SuggestOptions struct {
+	// Model is the name of the autocomplete model used to complete the partial query.  If
+	// empty then no completions are returned.
+	Model string
+
+	// Fields returned in results.  Keep this small for fast responses.
+	Fields []string
+
+	// Limit is the number of results to return.  Defaults to 5.
+	Limit int
+
+	// Filter to be applied to results.
+	Filter Filter
+
+	// Tracking configuration.  Set Tracking.QueryID to the same value for each call made
+	// as the user types, and increment Tracking.Sequence.
+	Tracking Tracking
+}
+
+// Suggestions are the completions and results for a partial query.
+type Suggestions struct {
+	// Completions of the partial query, in order of relevance.
+	Completions []string
+
+	// Results of searching for the partial query.
+	Results *Results
+}
+
+// Suggest returns completions (using an autocomplete model) and a small set of results for
+// the partial query text, as used in search-as-you-type interfaces.  The completion and the
+// search are run concurrently.
+func (q *Query) Suggest(ctx context.Context, partial string, opts SuggestOptions) (*Suggestions, error) {
+	limit := opts.Limit
+	if limit == 0 {
+		limit = 5
+	}
+
+	var completions []string
+	var completeErr error
+	var wg sync.WaitGroup
+	if opts.Model != "" && strings.TrimSpace(partial) != "" {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			resp, err := acpb.NewQueryClient(q.c.ClientConn).AutoComplete(q.c.newContext(ctx), &acpb.AutoCompleteRequest{
+				Model: &acpb.Model{
+					Name: opts.Model,
+				},
+				Phrase: partial,
+				Terms:  strings.Fields(partial),
+			})
+			if err != nil {
+				completeErr = err
+				return
+			}
+			completions = resp.Phrases
+		}()
+	}
+
+	results, err := q.Search(ctx, &Request{
+		IndexQuery: IndexQuery{
+			Text: partial,
+		},
+		Fields:   opts.Fields,
+		Limit:    limit,
+		Filter:   opts.Filter,
+		Tracking: opts.Tracking,
+	})
+	wg.Wait()
+	if err != nil {
+		return nil, err
+	}
+	if completeErr != nil {
+		return nil, completeErr
+	}
+
+	return &Suggestions{
+		Completions: completions,
+		Results:     results,
+	}, nil
+}
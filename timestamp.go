@@ -0,0 +1,171 @@
+package sajari
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// Values of TIMESTAMP fields are stored by the engine as the number of seconds since the
+// Unix epoch.  time.Time values (in records, mutations and filters) are written in this
+// form, with a fractional part if they have sub-second precision.
+
+// formatTimestamp returns the engine representation of t.
+func formatTimestamp(t time.Time) string {
+	sec, nsec := t.Unix(), int64(t.Nanosecond())
+	if nsec == 0 {
+		return strconv.FormatInt(sec, 10)
+	}
+
+	// t.Unix() rounds down, so before the epoch the fractional part counts
+	// forward from a more negative second.
+	sign := ""
+	if sec < 0 {
+		sign = "-"
+		sec, nsec = -(sec + 1), 1e9-nsec
+	}
+	return sign + strconv.FormatInt(sec, 10) + "." + strings.TrimRight(fmt.Sprintf("%09d", nsec), "0")
+}
+
+// parseTimestamp parses a TIMESTAMP value s, which is either a number of seconds since the
+// Unix epoch (possibly with a fractional part), or a time in RFC 3339 format.
+func parseTimestamp(s string) (time.Time, error) {
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return time.Unix(n, 0), nil
+	}
+
+	if t, ok := parseDecimalTimestamp(s); ok {
+		return t, nil
+	}
+
+	if strings.ContainsAny(s, "Tt") {
+		return time.Parse(time.RFC3339Nano, s)
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid timestamp %q: expected seconds since the Unix epoch or RFC 3339", s)
+	}
+	sec := int64(f)
+	return time.Unix(sec, int64((f-float64(sec))*1e9)), nil
+}
+
+// parseDecimalTimestamp parses s of the form [-]seconds.fraction with at most nine digits
+// in the fraction, as written by formatTimestamp, without losing precision to floating
+// point.
+func parseDecimalTimestamp(s string) (time.Time, bool) {
+	dot := strings.IndexByte(s, '.')
+	if dot < 0 {
+		return time.Time{}, false
+	}
+	whole, frac := s[:dot], s[dot+1:]
+	if len(frac) == 0 || len(frac) > 9 || strings.HasPrefix(whole, "+") {
+		return time.Time{}, false
+	}
+
+	sec, err := strconv.ParseInt(whole, 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+	nsec, err := strconv.ParseUint(frac+strings.Repeat("0", 9-len(frac)), 10, 64)
+	if err != nil {
+		return time.Time{}, false
+	}
+
+	if strings.HasPrefix(whole, "-") {
+		return time.Unix(sec, -int64(nsec)), true
+	}
+	return time.Unix(sec, int64(nsec)), true
+}
+
+// WithTimestampValues configures the client to return values of TIMESTAMP fields as time.Time
+// (or []time.Time for repeated fields) in records returned by Get, GetMulti and Query.Search,
+// rather than strings.  Field types are looked up using Schema.CachedFields, so enabling the
+// schema cache (see WithSchemaCache) is recommended.
+func WithTimestampValues() Opt {
+	return func(c *Client) {
+		c.timestampValues = true
+	}
+}
+
+// timestampFields returns the set of TIMESTAMP fields in the collection, or nil if
+// WithTimestampValues has not been set.
+func (c *Client) timestampFields(ctx context.Context) (map[string]bool, error) {
+	if !c.timestampValues {
+		return nil, nil
+	}
+
+	fs, err := c.Schema().CachedFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	out := make(map[string]bool)
+	for _, f := range fs {
+		if f.Type == TypeTimestamp {
+			out[f.Name] = true
+		}
+	}
+	return out, nil
+}
+
+// convertTimestamps replaces the values of TIMESTAMP fields ts in values with time.Time
+// (or []time.Time).  Keys of values are mapped to field names by aliases (which can be nil).
+func convertTimestamps(values map[string]interface{}, ts map[string]bool, aliases fieldAliases) error {
+	if len(ts) == 0 {
+		return nil
+	}
+
+	for k, v := range values {
+		field := k
+		if f, ok := aliases.field(k); ok {
+			field = f
+		}
+		if !ts[field] {
+			continue
+		}
+
+		switch v := v.(type) {
+		case string:
+			t, err := parseTimestamp(v)
+			if err != nil {
+				return fmt.Errorf("field %q: %v", k, err)
+			}
+			values[k] = t
+
+		case []string:
+			out := make([]time.Time, 0, len(v))
+			for _, s := range v {
+				t, err := parseTimestamp(s)
+				if err != nil {
+					return fmt.Errorf("field %q: %v", k, err)
+				}
+				out = append(out, t)
+			}
+			values[k] = out
+		}
+	}
+	return nil
+}
+
+// convertResultTimestamps applies convertTimestamps to the values of each result in rs,
+// if WithTimestampValues has been set.
+func (c *Client) convertResultTimestamps(ctx context.Context, rs *Results, aliases fieldAliases) error {
+	if !c.timestampValues || len(rs.Results) == 0 {
+		return nil
+	}
+
+	ts, err := c.timestampFields(ctx)
+	if err != nil {
+		return err
+	}
+	for _, r := range rs.Results {
+		if err := convertTimestamps(r.Values, ts, aliases); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,76 @@
+package sajari
+
+import (
+	"testing"
+	"time"
+)
+
+func TestTimestampRoundTrip(t *testing.T) {
+	tests := []time.Time{
+		time.Date(2017, time.March, 4, 5, 6, 7, 123456789, time.UTC),
+		time.Date(2017, time.March, 4, 5, 6, 7, 1, time.FixedZone("AEST", 10*60*60)),
+		time.Date(1969, time.December, 31, 23, 59, 59, 500000000, time.UTC),
+		time.Unix(1500000000, 0),
+		time.Unix(-2, 250000000),
+		time.Unix(0, 1),
+	}
+
+	for _, tt := range tests {
+		s := formatTimestamp(tt)
+		got, err := parseTimestamp(s)
+		if err != nil {
+			t.Errorf("parseTimestamp(%q) error: %v", s, err)
+			continue
+		}
+		if !got.Equal(tt) {
+			t.Errorf("parseTimestamp(formatTimestamp(%v)) = %v", tt, got)
+		}
+		if got.Nanosecond() != tt.Nanosecond() {
+			t.Errorf("parseTimestamp(formatTimestamp(%v)) nanoseconds = %d, expected %d", tt, got.Nanosecond(), tt.Nanosecond())
+		}
+	}
+}
+
+func TestFormatTimestamp(t *testing.T) {
+	tests := []struct {
+		t    time.Time
+		want string
+	}{
+		{time.Unix(1500000000, 0), "1500000000"},
+		{time.Unix(1500000000, 500000000), "1500000000.5"},
+		{time.Unix(1500000000, 123456789), "1500000000.123456789"},
+		{time.Unix(0, 1), "0.000000001"},
+		{time.Unix(-1, 500000000), "-0.5"},
+		{time.Unix(-2, 250000000), "-1.75"},
+	}
+
+	for _, tt := range tests {
+		if got := formatTimestamp(tt.t); got != tt.want {
+			t.Errorf("formatTimestamp(%v) = %q, expected %q", tt.t, got, tt.want)
+		}
+	}
+}
+
+func TestParseTimestamp(t *testing.T) {
+	tests := []struct {
+		s    string
+		want time.Time
+	}{
+		{"1500000000", time.Unix(1500000000, 0)},
+		{"1500000000.25", time.Unix(1500000000, 250000000)},
+		{"-0.5", time.Unix(-1, 500000000)},
+		{"2017-07-14T02:40:00.5Z", time.Unix(1500000000, 500000000)},
+		{"1.5e9", time.Unix(1500000000, 0)},
+	}
+
+	for _, tt := range tests {
+		got, err := parseTimestamp(tt.s)
+		if err != nil {
+			t.Errorf("parseTimestamp(%q) error: %v", tt.s, err)
+			continue
+		}
+		if !got.Equal(tt.want) {
+			t.Errorf("parseTimestamp(%q) = %v, expected %v", tt.s, got, tt.want)
+		}
+	}
+}
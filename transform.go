@@ -1,5 +1,10 @@
 package sajari
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Transform is a definition of a transformation applied to a Request
 // which is applied before the Request is executed.
 type Transform string
@@ -17,3 +22,93 @@ const (
 	// SplitIndexFields splits index fields into terms.
 	SplitIndexedFieldsTransform Transform = "split-indexed-fields"
 )
+
+// TransformInfo describes a transform.
+type TransformInfo struct {
+	// Transform is the transform identifier.
+	Transform Transform
+
+	// Description of what the transform does.
+	Description string
+}
+
+// knownTransforms are the transforms used when adding records which are known to
+// this package.
+var knownTransforms = []TransformInfo{
+	{SplitStopStemIndexedFieldsTranform, "splits indexed fields into terms, removes stop words and stems what remains"},
+	{StopStemTransform, "removes stop terms and stems terms"},
+	{SplitIndexedFieldsTransform, "splits indexed fields into terms"},
+}
+
+// KnownTransforms returns the transforms used when adding records which are known to
+// this package.  The engine does not currently provide a way to list the transforms
+// it supports, so this list may not include transforms added to the engine since this
+// package was released.
+func KnownTransforms() []TransformInfo {
+	out := make([]TransformInfo, len(knownTransforms))
+	copy(out, knownTransforms)
+	return out
+}
+
+// Validate returns an error if t is not one of the transforms returned by KnownTransforms.
+// The error suggests the closest known transform if there is a likely match.
+func (t Transform) Validate() error {
+	best, bestDist := Transform(""), -1
+	for _, kt := range knownTransforms {
+		if kt.Transform == t {
+			return nil
+		}
+		if d := editDistance(string(t), string(kt.Transform)); bestDist < 0 || d < bestDist {
+			best, bestDist = kt.Transform, d
+		}
+	}
+
+	if bestDist >= 0 && bestDist <= len(best)/3 {
+		return fmt.Errorf("unknown transform %q (did you mean %q?)", t, best)
+	}
+	names := make([]string, 0, len(knownTransforms))
+	for _, kt := range knownTransforms {
+		names = append(names, string(kt.Transform))
+	}
+	return fmt.Errorf("unknown transform %q (should be one of %v)", t, strings.Join(names, ", "))
+}
+
+// WithTransformValidation configures the client to check the transforms passed to Add
+// and AddMulti using Transform.Validate, returning an error rather than adding records
+// with an unknown (e.g. misspelt) transform.
+func WithTransformValidation() Opt {
+	return func(c *Client) {
+		c.validateTransforms = true
+	}
+}
+
+// editDistance returns the Levenshtein distance between a and b.
+func editDistance(a, b string) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
+
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
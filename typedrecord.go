@@ -0,0 +1,146 @@
+package sajari
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"golang.org/x/net/context"
+)
+
+// NewTypedRecord returns a RecordBuilder which checks field names and value types against
+// the collection schema, so that mistakes (e.g. misspelt field names) are caught when the
+// record is built rather than when it is added.  The schema is fetched using
+// Schema.CachedFields, so enable the schema cache (see WithSchemaCache) in long-lived
+// services to avoid fetching the schema for each record.
+func (c *Client) NewTypedRecord(ctx context.Context) (*RecordBuilder, error) {
+	fs, err := c.Schema().CachedFields(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]Field, len(fs))
+	for _, f := range fs {
+		fields[f.Name] = f
+	}
+	return &RecordBuilder{
+		fields: fields,
+		rec:    make(Record),
+	}, nil
+}
+
+// RecordBuilder builds a Record, checking each value against the collection schema.  Use
+// Client.NewTypedRecord to create one.
+//
+// Setters return the builder so that calls can be chained.  The first error is retained and
+// returned by Record, and subsequent calls to setters have no effect.  A RecordBuilder is
+// not safe for concurrent use.
+type RecordBuilder struct {
+	fields map[string]Field
+	rec    Record
+	err    error
+}
+
+// set checks that field is in the schema with type t and the given repetition, and if so
+// sets the field to v.
+func (b *RecordBuilder) set(field string, t Type, repeated bool, v interface{}) *RecordBuilder {
+	if b.err != nil {
+		return b
+	}
+
+	f, ok := b.fields[field]
+	switch {
+	case !ok:
+		b.err = fmt.Errorf("sajari: field %q is not in the schema", field)
+
+	case f.Type != t:
+		b.err = fmt.Errorf("sajari: field %q has type %v, cannot set %v value", field, f.Type, t)
+
+	case f.Repeated != repeated:
+		if f.Repeated {
+			b.err = fmt.Errorf("sajari: field %q is repeated, cannot set single value", field)
+		} else {
+			b.err = fmt.Errorf("sajari: field %q is not repeated, cannot set repeated value", field)
+		}
+
+	default:
+		b.rec[field] = v
+	}
+	return b
+}
+
+// SetBody sets the record body (see BodyField).
+func (b *RecordBuilder) SetBody(body string) *RecordBuilder {
+	if b.err == nil {
+		b.rec[BodyField] = body
+	}
+	return b
+}
+
+// SetString sets the string field to v.
+func (b *RecordBuilder) SetString(field, v string) *RecordBuilder {
+	return b.set(field, TypeString, false, v)
+}
+
+// SetStrings sets the repeated string field to vs.
+func (b *RecordBuilder) SetStrings(field string, vs []string) *RecordBuilder {
+	return b.set(field, TypeString, true, vs)
+}
+
+// SetInt sets the integer field to v.
+func (b *RecordBuilder) SetInt(field string, v int64) *RecordBuilder {
+	return b.set(field, TypeInteger, false, v)
+}
+
+// SetInts sets the repeated integer field to vs.
+func (b *RecordBuilder) SetInts(field string, vs []int64) *RecordBuilder {
+	return b.set(field, TypeInteger, true, vs)
+}
+
+// SetFloat sets the float field to v.
+func (b *RecordBuilder) SetFloat(field string, v float64) *RecordBuilder {
+	return b.set(field, TypeFloat, false, v)
+}
+
+// SetBool sets the boolean field to v.
+func (b *RecordBuilder) SetBool(field string, v bool) *RecordBuilder {
+	return b.set(field, TypeBoolean, false, v)
+}
+
+// SetTime sets the timestamp field to v.
+func (b *RecordBuilder) SetTime(field string, v time.Time) *RecordBuilder {
+	return b.set(field, TypeTimestamp, false, v)
+}
+
+// Record returns the built Record, or the first error encountered when setting values.  It
+// also returns an error naming the required fields in the schema which have not been set.
+// The returned Record is a copy, so the builder can continue to be used without changing it.
+func (b *RecordBuilder) Record() (Record, error) {
+	if b.err != nil {
+		return nil, b.err
+	}
+
+	var missing []string
+	for name, f := range b.fields {
+		if name == IDField {
+			// Set by the engine when the record is added.
+			continue
+		}
+		if _, ok := b.rec[name]; f.Required && !ok {
+			missing = append(missing, fmt.Sprintf("%q", name))
+		}
+	}
+	switch len(missing) {
+	case 0:
+		rec := make(Record, len(b.rec))
+		for k, v := range b.rec {
+			rec[k] = v
+		}
+		return rec, nil
+	case 1:
+		return nil, fmt.Errorf("sajari: required field %v is not set", missing[0])
+	}
+	sort.Strings(missing)
+	return nil, fmt.Errorf("sajari: required fields %v are not set", strings.Join(missing, ", "))
+}
@@ -0,0 +1,34 @@
+package sajari
+
+import "testing"
+
+func TestRecordBuilderRecordIsCopy(t *testing.T) {
+	b := &RecordBuilder{
+		fields: map[string]Field{
+			"title": {Name: "title", Type: TypeString, Required: true},
+			"count": {Name: "count", Type: TypeInteger},
+		},
+		rec: make(Record),
+	}
+
+	rec, err := b.SetString("title", "first").Record()
+	if err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+
+	b.SetString("title", "second").SetInt("count", 2)
+	if got := rec["title"]; got != "first" {
+		t.Errorf("rec[%q] = %v after reusing the builder, expected %q", "title", got, "first")
+	}
+	if _, ok := rec["count"]; ok {
+		t.Errorf("rec[%q] set after reusing the builder", "count")
+	}
+
+	rec2, err := b.Record()
+	if err != nil {
+		t.Fatalf("Record() error: %v", err)
+	}
+	if rec2["title"] != "second" || rec2["count"] != int64(2) {
+		t.Errorf("second Record() = %v, expected title second and count 2", rec2)
+	}
+}